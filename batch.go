@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// decompDir runs decompGraph on each graph file in the directory, in alphabetical order, and returns the
+// exit code of the whole batch. Running out of time on a single graph doesn't stop the batch.
+func decompDir(dir string, quiet bool, decompGraph func(graphPath string) int) int {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not read the input directory:", err)
+		return exitError
+	}
+
+	output := 0
+	count := 0
+	start := time.Now()
+
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(dir, f.Name())
+		count++
+
+		startFile := time.Now()
+		code := decompGraph(path)
+		msec := time.Now().Sub(startFile).Seconds() * float64(time.Second/time.Millisecond)
+
+		if !quiet {
+			fmt.Printf("Time for %s: %.5f ms\n\n", path, msec)
+		}
+
+		switch code {
+		case exitInterrupt:
+			return code
+		case exitError, exitIncorrect:
+			output = code
+		}
+	}
+
+	msec := time.Now().Sub(start).Seconds() * float64(time.Second/time.Millisecond)
+	if !quiet {
+		fmt.Printf("Decomposed %d graphs in %.5f ms\n", count, msec)
+	}
+
+	return output
+}
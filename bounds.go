@@ -0,0 +1,195 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// computeLowerBound returns a cheap lower bound on the hypertree width of a graph. Every clique of the
+// primal graph has to be contained in some bag, so the fractional edge cover of any clique bounds the width
+// from below. The cliques are found greedily, starting from each vertex.
+func computeLowerBound(g lib.Graph) int {
+	neighbours := make(map[int]map[int]bool)
+	for _, e := range g.Edges.Slice() {
+		for _, v := range e.Vertices {
+			if neighbours[v] == nil {
+				neighbours[v] = make(map[int]bool)
+			}
+			for _, w := range e.Vertices {
+				if v != w {
+					neighbours[v][w] = true
+				}
+			}
+		}
+	}
+
+	output := 1
+	for _, v := range g.Vertices() {
+		adjacent, ok := neighbours[v]
+		if !ok { // only occurs in special edges
+			continue
+		}
+		candidates := make([]int, 0, len(adjacent))
+		for w := range adjacent {
+			candidates = append(candidates, w)
+		}
+		sort.Slice(candidates, func(i, j int) bool {
+			if len(neighbours[candidates[i]]) != len(neighbours[candidates[j]]) {
+				return len(neighbours[candidates[i]]) > len(neighbours[candidates[j]])
+			}
+			return candidates[i] < candidates[j]
+		})
+
+		clique := []int{v}
+	CANDIDATES:
+		for _, w := range candidates {
+			for _, u := range clique {
+				if !neighbours[w][u] {
+					continue CANDIDATES
+				}
+			}
+			clique = append(clique, w)
+		}
+
+		// the integral cover is at least the fractional one, rounded up
+		bound := int(math.Ceil(fractionalCover(clique, g.Edges.Slice()) - 1e-6))
+		if bound > output {
+			output = bound
+		}
+	}
+
+	return output
+}
+
+// widthBound is a bound on the width of a graph, along with the method used to compute it
+type widthBound struct {
+	width  int
+	method string
+}
+
+// computeUpperBound returns a cheap upper bound on the hypertree width of a graph, or on its generalized
+// hypertree width if ghd is set. Acyclic graphs, which the GYÖ reduct shrinks to at most one edge, have width
+// 1. Otherwise, a greedy GHD bounds the generalized hypertree width, and thus the hypertree width up to
+// the factor 3 and an additive 1, and any graph has a decomp of width |E|. Special edges are not taken
+// into account.
+func computeUpperBound(g lib.Graph, ghd bool) widthBound {
+	if g.Edges.Len() == 0 {
+		return widthBound{width: 0, method: "empty graph"}
+	}
+	if reduced, _ := g.GYÖReduct(); reduced.Edges.Len() <= 1 {
+		return widthBound{width: 1, method: "acyclic, as shown by the GYÖ reduct"}
+	}
+
+	greedy := greedyGHDWidth(g)
+	if ghd {
+		return widthBound{width: greedy, method: "greedy GHD along a min-degree elimination ordering"}
+	}
+	if 3*greedy+1 < g.Edges.Len() {
+		return widthBound{width: 3*greedy + 1, method: fmt.Sprintf("3 * %d + 1, from a greedy GHD of width %d along a min-degree elimination ordering", greedy, greedy)}
+	}
+	return widthBound{width: g.Edges.Len(), method: "number of edges"}
+}
+
+// greedyGHDWidth computes the width of a GHD built greedily: the vertices of the primal graph are
+// eliminated in the order of their smallest degree, each creating a bag of the vertex and its neighbours,
+// which gets covered by repeatedly picking the edge that covers the most of its uncovered vertices
+func greedyGHDWidth(g lib.Graph) int {
+	neighbours := make(map[int]map[int]bool)
+	for _, e := range g.Edges.Slice() {
+		for _, v := range e.Vertices {
+			if neighbours[v] == nil {
+				neighbours[v] = make(map[int]bool)
+			}
+			for _, w := range e.Vertices {
+				if v != w {
+					neighbours[v][w] = true
+				}
+			}
+		}
+	}
+
+	edges := g.Edges.Slice()
+	output := 1
+	for len(neighbours) > 0 {
+		next, degree := 0, -1
+		for v, adjacent := range neighbours {
+			if degree < 0 || len(adjacent) < degree || (len(adjacent) == degree && v < next) {
+				next, degree = v, len(adjacent)
+			}
+		}
+
+		bag := []int{next}
+		for w := range neighbours[next] {
+			bag = append(bag, w)
+		}
+		if width := greedyCover(bag, edges); width > output {
+			output = width
+		}
+
+		// the neighbours of the eliminated vertex form a clique of the remaining primal graph
+		for _, u := range bag[1:] {
+			delete(neighbours[u], next)
+			for _, w := range bag[1:] {
+				if u != w {
+					neighbours[u][w] = true
+				}
+			}
+		}
+		delete(neighbours, next)
+	}
+
+	return output
+}
+
+// greedyCover returns the number of edges a greedy set cover of the bag uses
+func greedyCover(bag []int, edges []Edge) int {
+	uncovered := make(map[int]bool, len(bag))
+	for _, v := range bag {
+		uncovered[v] = true
+	}
+
+	output := 0
+	for len(uncovered) > 0 {
+		best, bestCount := -1, 0
+		for i, e := range edges {
+			count := 0
+			for _, v := range e.Vertices {
+				if uncovered[v] {
+					count++
+				}
+			}
+			if count > bestCount {
+				best, bestCount = i, count
+			}
+		}
+		if best < 0 { // a vertex of no edge, which only occurs in special edges
+			break
+		}
+		for _, v := range edges[best].Vertices {
+			delete(uncovered, v)
+		}
+		output++
+	}
+
+	return output
+}
+
+// printBounds reports an interval containing the width of a graph, without searching for a decomp
+func printBounds(w io.Writer, g lib.Graph, ghd bool) {
+	lower := widthBound{width: computeLowerBound(g), method: "fractional edge cover of a greedy clique of the primal graph"}
+	upper := computeUpperBound(g, ghd)
+	if upper.width == 0 {
+		lower = upper
+	}
+	if lower.width < 2 && upper.width > 1 { // only acyclic graphs have width 1
+		lower = widthBound{width: 2, method: "cyclic, as the GYÖ reduct leaves more than one edge"}
+	}
+
+	fmt.Fprintf(w, "Lower bound: %d (%s)\n", lower.width, lower.method)
+	fmt.Fprintf(w, "Upper bound: %d (%s)\n", upper.width, upper.method)
+	fmt.Fprintf(w, "Width in: [%d, %d]\n", lower.width, upper.width)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+	"github.com/cem-okulmus/log-k-decomp/logk"
+)
+
+// componentResult keeps track of the decomposition of a single connected component
+type componentResult struct {
+	Graph  Graph
+	Decomp Decomp
+	Err    error // why no decomp was found, if the search was inconclusive
+}
+
+// Width returns the width of the component decomposition, or -1 if none was found
+func (c componentResult) Width() int {
+	if logk.IsEmptyDecomp(c.Decomp) {
+		return -1
+	}
+	return c.Decomp.CheckWidth()
+}
+
+// getConnectedComponents splits a graph into its connected components, sorted by size
+func getConnectedComponents(graph Graph) []Graph {
+	comps, _, _ := graph.GetComponents(lib.Edges{})
+
+	sort.Slice(comps, func(i, j int) bool {
+		return comps[i].Len() > comps[j].Len()
+	})
+
+	return comps
+}
+
+// findDecompGraph decomposes G with solver. A search that fails since no decomp exists returns the empty
+// decomp without an error, while all other errors of the search are returned instead of causing a panic,
+// s.t. an incomplete or cancelled search can be told apart from a failed one.
+func findDecompGraph(solver logk.Algorithm, G Graph) (Decomp, error) {
+	resultSolver, ok := solver.(logk.ResultAlgorithm)
+	if !ok {
+		return solver.FindDecompGraph(G), nil
+	}
+
+	decomp, err := resultSolver.FindDecompGraphResult(G)
+	if errors.Is(err, logk.ErrNoDecomposition) {
+		return Decomp{}, nil
+	}
+	return decomp, err
+}
+
+// inconclusive checks if err only tells that a search didn't finish, i.e. it was incomplete, cancelled or
+// timed out, rather than that it went wrong
+func inconclusive(err error) bool {
+	return errors.Is(err, logk.ErrIncomplete) || errors.Is(err, logk.ErrCancelled) || errors.Is(err, logk.ErrTimeout)
+}
+
+// decompComponents decomposes each of the connected components separately, to report on all of them.
+// Inconclusive searches are reported per component, other errors stop at the first component.
+func decompComponents(solver logk.Algorithm, comps []Graph) ([]componentResult, error) {
+	var output []componentResult
+
+	for _, comp := range comps {
+		decomp, err := findDecompGraph(solver, comp)
+		if err != nil && !inconclusive(err) {
+			return nil, err
+		}
+		output = append(output, componentResult{Graph: comp, Decomp: decomp, Err: err})
+	}
+
+	return output, nil
+}
+
+// decompJoined decomposes each of the connected components separately, and combines their decomps as
+// joinComponents does. It stops at the first component without a decomp, since then the graph has none,
+// or whose search returned an error.
+func decompJoined(solver logk.Algorithm, comps []Graph, graph Graph) (Decomp, error) {
+	results := make([]componentResult, 0, len(comps))
+
+	for _, comp := range comps {
+		decomp, err := findDecompGraph(solver, comp)
+		if err != nil {
+			return Decomp{}, err
+		}
+		if logk.IsEmptyDecomp(decomp) {
+			return Decomp{}, nil
+		}
+		results = append(results, componentResult{Graph: comp, Decomp: decomp})
+	}
+
+	return joinComponents(results, graph), nil
+}
+
+// joinComponents combines the decomps of all components of the graph into one, by attaching them to an
+// empty root. If any component has no decomp, neither does the graph.
+func joinComponents(results []componentResult, graph Graph) Decomp {
+	root := lib.Node{Bag: []int{}, Cover: lib.NewEdges([]lib.Edge{})}
+
+	for i := range results {
+		if logk.IsEmptyDecomp(results[i].Decomp) {
+			return Decomp{}
+		}
+		root.Children = append(root.Children, results[i].Decomp.Root)
+	}
+
+	return Decomp{Graph: graph, Root: root}
+}
+
+// outputComponents prints the size and width of each component, as well as the overall width
+func outputComponents(results []componentResult) {
+	fmt.Println("Number of components: ", len(results))
+
+	maxWidth := 0
+	failed, incomplete := false, false
+
+	for i := range results {
+		width := results[i].Width()
+		if width < 0 {
+			outcome := "FAIL"
+			if results[i].Err != nil {
+				outcome = "INCOMPLETE"
+				incomplete = true
+			} else {
+				failed = true
+			}
+			fmt.Printf("Component %d: %d edges, %d vertices, Width: %s\n", i+1,
+				results[i].Graph.Edges.Len(), len(results[i].Graph.Vertices()), outcome)
+			continue
+		}
+		if width > maxWidth {
+			maxWidth = width
+		}
+		fmt.Printf("Component %d: %d edges, %d vertices, Width: %d\n", i+1,
+			results[i].Graph.Edges.Len(), len(results[i].Graph.Vertices()), width)
+	}
+
+	if failed {
+		fmt.Println("Overall Width:  FAIL")
+	} else if incomplete { // no component is known to fail, but some may
+		fmt.Println("Overall Width:  INCOMPLETE")
+	} else {
+		fmt.Println("Overall Width: ", maxWidth)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+	"github.com/cem-okulmus/log-k-decomp/logk"
+)
+
+// countingSolver fails on every graph, counting how often it was asked
+type countingSolver struct {
+	calls int
+}
+
+func (c *countingSolver) Name() string { return "CountingSolver" }
+
+func (c *countingSolver) FindDecomp() Decomp { return Decomp{} }
+
+func (c *countingSolver) FindDecompGraph(G Graph) Decomp {
+	c.calls++
+	return Decomp{}
+}
+
+func (c *countingSolver) SetWidth(K int) {}
+
+// resultSolver fails on every graph with the given error
+type resultSolver struct {
+	countingSolver
+	err error
+}
+
+func (r *resultSolver) FindDecompResult() (Decomp, error) { return Decomp{}, r.err }
+
+func (r *resultSolver) FindDecompGraphResult(G Graph) (Decomp, error) { return Decomp{}, r.err }
+
+// twoTriangles is a graph of two components, each of hypertree width 2
+const twoTriangles = "a0(x0,x1), a1(x1,x2), a2(x2,x0), b0(y0,y1), b1(y1,y2), b2(y2,y0)."
+
+func TestDecompJoined(t *testing.T) {
+	graph, _ := lib.GetGraph(twoTriangles)
+	comps := getConnectedComponents(graph)
+	if len(comps) != 2 {
+		t.Fatalf("got %d components, want 2", len(comps))
+	}
+
+	for _, K := range []int{1, 2} {
+		solver := &logk.LogKDecomp{Graph: graph, K: K, BalFactor: 2}
+		decomp, err := decompJoined(solver, comps, graph)
+		if err != nil {
+			t.Fatalf("K = %d: %v", K, err)
+		}
+
+		if solver.Graph.Edges.Len() != graph.Edges.Len() {
+			t.Errorf("K = %d: the graph of the solver was left at %v", K, solver.Graph)
+		}
+		if got, want := !logk.IsEmptyDecomp(decomp), K == 2; got != want {
+			t.Fatalf("K = %d: found a decomp: %v, want %v", K, got, want)
+		}
+		if K == 2 && !decomp.Correct(graph) {
+			t.Errorf("K = %d: incorrect decomp %v", K, decomp)
+		}
+	}
+}
+
+func TestDecompJoinedStopsEarly(t *testing.T) {
+	graph, _ := lib.GetGraph(twoTriangles)
+
+	var solver countingSolver
+	decomp, err := decompJoined(&solver, getConnectedComponents(graph), graph)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !logk.IsEmptyDecomp(decomp) {
+		t.Errorf("got a decomp, though no component has one: %v", decomp)
+	}
+	if solver.calls != 1 {
+		t.Errorf("searched %d components, want only the first one", solver.calls)
+	}
+}
+
+func TestFindDecompGraphErrors(t *testing.T) {
+	graph, _ := lib.GetGraph(twoTriangles)
+
+	// the empty decomp tells that none exists, but not that the search didn't finish
+	tests := []struct {
+		err  error
+		want error
+	}{
+		{logk.ErrNoDecomposition, nil},
+		{logk.ErrIncomplete, logk.ErrIncomplete},
+		{logk.ErrCancelled, logk.ErrCancelled},
+		{logk.ErrTimeout, logk.ErrTimeout},
+		{logk.ErrDepthExceeded, logk.ErrDepthExceeded},
+	}
+
+	for _, tt := range tests {
+		decomp, err := findDecompGraph(&resultSolver{err: tt.err}, graph)
+		if err != tt.want || !logk.IsEmptyDecomp(decomp) {
+			t.Errorf("%v: got the error %v, want %v", tt.err, err, tt.want)
+		}
+		if _, err := decompJoined(&resultSolver{err: tt.err}, getConnectedComponents(graph), graph); err != tt.want {
+			t.Errorf("%v: decompJoined returned the error %v, want %v", tt.err, err, tt.want)
+		}
+	}
+}
+
+func TestComponentWidths(t *testing.T) {
+	// a triangle, of width 2, and a single edge, of width 1
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"width 1", []string{"-width", "1"}, []string{
+			"Component 1: 3 edges, 3 vertices, Width: FAIL",
+			"Component 2: 1 edges, 3 vertices, Width: 1",
+			"Overall Width:  FAIL",
+		}},
+		{"width 2", []string{"-width", "2"}, []string{
+			"Component 1: 3 edges, 3 vertices, Width: 2",
+			"Component 2: 1 edges, 3 vertices, Width: 1",
+			"Overall Width:  2",
+		}},
+		// every recursive call gets abandoned, so no component is known to fail
+		{"incomplete", []string{"-width", "2", "-subtimeout", "1ns"}, []string{
+			"Component 1: 3 edges, 3 vertices, Width: INCOMPLETE",
+			"Component 2: 1 edges, 3 vertices, Width: INCOMPLETE",
+			"Overall Width:  INCOMPLETE",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"-graph", "testdata/twowidths.hg", "-logk", "-components"}, tt.args...)
+			stdout, stderr, code := runMain(t, args...)
+			if code != 0 {
+				t.Fatalf("got exit code %d:\n%s", code, stderr)
+			}
+			for _, line := range tt.want {
+				if !strings.Contains(stdout, line+"\n") {
+					t.Errorf("missing %q in the output:\n%s", line, stdout)
+				}
+			}
+		})
+	}
+}
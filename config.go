@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// applyConfig sets the flags listed in a JSON config file, an object mapping flag names to their values,
+// e.g. {"logk": true, "heuristic": "1,2", "balfactor": 3}. Flags given explicitly on the command line
+// take precedence over the file. Unknown flags are rejected, just like on the command line.
+func applyConfig(flagSet *flag.FlagSet, data []byte) error {
+	var config map[string]json.RawMessage
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&config); err != nil {
+		return fmt.Errorf("invalid config: %v", err)
+	}
+
+	explicit := make(map[string]bool)
+	flagSet.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	names := make([]string, 0, len(config))
+	for name := range config {
+		names = append(names, name)
+	}
+	sort.Strings(names) // report the first unknown flag deterministically
+
+	for _, name := range names {
+		if flagSet.Lookup(name) == nil || name == "config" {
+			return fmt.Errorf("unknown flag %q in config", name)
+		}
+		if explicit[name] {
+			continue
+		}
+
+		value, err := configValue(config[name])
+		if err != nil {
+			return fmt.Errorf("flag %q in config: %v", name, err)
+		}
+		if err := flagSet.Set(name, value); err != nil {
+			return fmt.Errorf("flag %q in config: %v", name, err)
+		}
+	}
+
+	return nil
+}
+
+// configValue converts a JSON value into the text the flag would be given on the command line
+func configValue(raw json.RawMessage) (string, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return "", err
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool, json.Number:
+		return strings.TrimSpace(fmt.Sprint(v)), nil
+	default:
+		return "", fmt.Errorf("expected a string, number or boolean, got %s", raw)
+	}
+}
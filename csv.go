@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+)
+
+// csvHeader lists the columns of the timing summary
+var csvHeader = []string{"graph", "algorithm", "K", "width", "correct", "heuristic ms", "hingetree ms",
+	"decomposition ms", "total ms"}
+
+// appendCSV adds one row summarising a run to the CSV file at path, writing the header if the file is new
+func appendCSV(path string, graph string, algorithm string, K int, width int, correct bool, times []labelTime) error {
+	_, err := os.Stat(path)
+	isNew := os.IsNotExist(err)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	labels := make(map[string]float64)
+	var total float64
+	for _, t := range times {
+		labels[t.label] = t.time
+		total = total + t.time
+	}
+
+	w := csv.NewWriter(f)
+	if isNew {
+		w.Write(csvHeader)
+	}
+	w.Write([]string{graph, algorithm, fmt.Sprint(K), fmt.Sprint(width), fmt.Sprint(correct),
+		fmt.Sprintf("%.5f", labels["Heuristic"]), fmt.Sprintf("%.5f", labels["Hingetree"]),
+		fmt.Sprintf("%.5f", labels["Decomposition"]), fmt.Sprintf("%.5f", total)})
+	w.Flush()
+
+	return w.Error()
+}
@@ -0,0 +1,131 @@
+package main
+
+// dedup.go removes duplicate edges, i.e. edges on the same set of vertices, which only enlarge the search
+// space, as any bag covering one of them covers all of them
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// duplicates describes the duplicate edges of a graph, and the edges repeating any of their vertices
+type duplicates struct {
+	removed map[int][]int // the names of the edges removed for each edge kept on the same vertices
+	loops   []int         // the names of the edges listing some vertex more than once
+}
+
+// count returns the number of edges that are duplicates of others
+func (d duplicates) count() int {
+	output := 0
+	for _, names := range d.removed {
+		output += len(names)
+	}
+	return output
+}
+
+// vertexSet returns the vertices of an edge without repetitions, in ascending order
+func vertexSet(e Edge) []int {
+	output := append([]int{}, e.Vertices...)
+	sort.Ints(output)
+
+	j := 0
+	for i := range output {
+		if i == 0 || output[i] != output[j-1] {
+			output[j] = output[i]
+			j++
+		}
+	}
+	return output[:j]
+}
+
+// setKey identifies a set of vertices in ascending order
+func setKey(vertices []int) string {
+	var key strings.Builder
+	for _, v := range vertices {
+		key.WriteString(strconv.Itoa(v))
+		key.WriteString(",")
+	}
+	return key.String()
+}
+
+// findDuplicates detects the duplicate edges of a graph. Of each set of duplicates, the first edge is kept,
+// unless a later one has a smaller weight.
+func findDuplicates(g Graph, weights map[int]float64) duplicates {
+	output := duplicates{removed: make(map[int][]int)}
+
+	kept := make(map[string]int) // the edge kept for each set of vertices
+	for _, e := range g.Edges.Slice() {
+		vertices := vertexSet(e)
+		if len(vertices) < len(e.Vertices) {
+			output.loops = append(output.loops, e.Name)
+		}
+
+		key := setKey(vertices)
+		first, ok := kept[key]
+		if !ok {
+			kept[key] = e.Name
+			continue
+		}
+		if weights != nil && weights[e.Name] < weights[first] {
+			kept[key] = e.Name
+			output.removed[e.Name] = append(output.removed[first], first)
+			delete(output.removed, first)
+			continue
+		}
+		output.removed[first] = append(output.removed[first], e.Name)
+	}
+
+	return output
+}
+
+// dedupGraph removes the duplicate edges found by findDuplicates, and the repetitions of vertices inside
+// the remaining edges
+func dedupGraph(g Graph, d duplicates) Graph {
+	removed := make(map[int]bool)
+	for _, names := range d.removed {
+		for _, name := range names {
+			removed[name] = true
+		}
+	}
+
+	var edges []Edge
+	for _, e := range g.Edges.Slice() {
+		if !removed[e.Name] {
+			edges = append(edges, Edge{Name: e.Name, Vertices: vertexSet(e)})
+		}
+	}
+
+	return Graph{Edges: lib.NewEdges(edges), Special: g.Special}
+}
+
+// restoreDeduped replaces the edges in the covers of a decomposition of the deduplicated graph by those of
+// the input, which may repeat some of their vertices. The removed duplicates need not be restored, as
+// every bag covering the edge kept in their stead covers them as well.
+func restoreDeduped(n lib.Node, original Graph) lib.Node {
+	edges := make(map[int]Edge, original.Edges.Len())
+	for _, e := range original.Edges.Slice() {
+		edges[e.Name] = e
+	}
+
+	var restore func(n lib.Node) lib.Node
+	restore = func(n lib.Node) lib.Node {
+		cover := make([]Edge, 0, n.Cover.Len())
+		for _, e := range n.Cover.Slice() {
+			if input, ok := edges[e.Name]; ok {
+				e = input
+			}
+			cover = append(cover, e)
+		}
+
+		output := lib.Node{Bag: n.Bag, Cover: lib.NewEdges(cover)}
+		for _, child := range n.Children {
+			output.Children = append(output.Children, restore(child))
+		}
+		return output
+	}
+
+	return restore(n)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cem-okulmus/log-k-decomp/logk"
+)
+
+// widthResult is the outcome of the search for a decomp of a single width
+type widthResult struct {
+	K      int
+	Decomp Decomp
+	Solver logk.Algorithm
+	Err    error
+}
+
+// searchWidths searches for the smallest width in [lowerBound, upperBound] for which search finds a decomp,
+// trying one width after another in ascending order. search reports whether to stop right away, e.g. since
+// the search got cancelled. If no width succeeds, the empty decomp is returned with the last width tried.
+func searchWidths(lowerBound, upperBound int, triedWidth *int64, search func(K int) (Decomp, bool)) (Decomp, int) {
+	decomp, width := Decomp{}, lowerBound
+
+	for K := lowerBound; K <= upperBound; K++ {
+		atomic.StoreInt64(triedWidth, int64(K))
+		var stop bool
+		decomp, stop = search(K)
+		width = K
+		if stop || !logk.IsEmptyDecomp(decomp) {
+			break
+		}
+	}
+
+	return decomp, width
+}
+
+// searchWidthsParallel searches for the smallest width in [lowerBound, upperBound] for which search finds a
+// decomp, running the searches of up to parallel widths at once. Each search gets its own context, s.t.
+// the searches of larger widths can be cancelled once a smaller width succeeds; all of them have stopped
+// once this returns. Alongside the decomp and its width, the solver producing it is returned, and whether
+// all smaller widths are known to fail. If ctx is cancelled before that is known, the smallest decomp found
+// so far is returned anyway. If none was found, an empty decomp is returned without a solver. The first
+// search to fail with an error stops all others, and its error is returned.
+func searchWidthsParallel(ctx context.Context, lowerBound, upperBound, parallel int, triedWidth *int64,
+	search func(ctx context.Context, K int) (Decomp, logk.Algorithm, error)) (widthResult, bool, error) {
+	results := make(chan widthResult, parallel) // buffered, so cancelled searches never block
+	cancels := make(map[int]context.CancelFunc)
+	failed := make(map[int]bool)
+
+	// stop the searches still running, and wait for them, s.t. none outlives the call
+	var wg sync.WaitGroup
+	defer func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+		wg.Wait()
+	}()
+
+	var best *widthResult
+	next := lowerBound      // the next width to start a search for
+	undecided := lowerBound // the smallest width not known to fail
+
+	for {
+		for ctx.Err() == nil && len(cancels) < parallel && next <= upperBound && (best == nil || next < best.K) {
+			K := next
+			ctxWidth, cancel := context.WithCancel(ctx)
+			cancels[K] = cancel
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				decomp, solver, err := search(ctxWidth, K)
+				results <- widthResult{K: K, Decomp: decomp, Solver: solver, Err: err}
+			}()
+			next++
+		}
+
+		if best != nil && undecided == best.K {
+			return *best, true, nil
+		}
+		if len(cancels) == 0 { // ctx got cancelled, or all widths failed
+			if best != nil {
+				return *best, false, nil
+			}
+			if undecided > upperBound {
+				undecided = upperBound // the last width tried, as reported by searchWidths
+			}
+			return widthResult{K: undecided}, ctx.Err() == nil, nil
+		}
+
+		result := <-results
+		cancels[result.K]()
+		delete(cancels, result.K)
+		if result.Err != nil {
+			return widthResult{K: result.K}, false, result.Err
+		}
+
+		// a decomp found is as good after ctx got cancelled as before, while a failure may only be due to it
+		if !logk.IsEmptyDecomp(result.Decomp) {
+			if best == nil || result.K < best.K {
+				best = &result
+				for K, cancel := range cancels { // larger widths are of no interest anymore
+					if K > best.K {
+						cancel()
+					}
+				}
+			}
+		} else if ctx.Err() == nil {
+			failed[result.K] = true
+		}
+
+		for failed[undecided] {
+			undecided++
+		}
+		atomic.StoreInt64(triedWidth, int64(undecided))
+	}
+}
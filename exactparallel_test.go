@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+	"github.com/cem-okulmus/log-k-decomp/logk"
+)
+
+// decompOfWidth returns a decomp whose root has a cover of K edges
+func decompOfWidth(K int) Decomp {
+	edges := make([]lib.Edge, K)
+	for i := range edges {
+		edges[i] = lib.Edge{Name: i, Vertices: []int{i}}
+	}
+	cover := lib.NewEdges(edges)
+	return Decomp{Graph: Graph{Edges: cover}, Root: lib.Node{Bag: cover.Vertices(), Cover: cover}}
+}
+
+func TestSearchWidths(t *testing.T) {
+	tests := []struct {
+		name      string
+		smallest  int // the smallest width with a decomp, 0 if none
+		stopAt    int // the width at which the search asks to stop, 0 if never
+		want      int
+		wantFound bool
+	}{
+		{"found at the lower bound", 2, 0, 2, true},
+		{"found in between", 4, 0, 4, true},
+		{"found at the upper bound", 5, 0, 5, true},
+		{"none found up to the upper bound", 0, 0, 5, false},
+		{"stopped", 4, 3, 3, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tried []int
+			var triedWidth int64
+			decomp, width := searchWidths(2, 5, &triedWidth, func(K int) (Decomp, bool) {
+				tried = append(tried, K)
+				if tt.smallest > 0 && K >= tt.smallest {
+					return decompOfWidth(K), false
+				}
+				return Decomp{}, K == tt.stopAt
+			})
+
+			if width != tt.want {
+				t.Errorf("got width %d, want %d", width, tt.want)
+			}
+			if found := !logk.IsEmptyDecomp(decomp); found != tt.wantFound {
+				t.Errorf("found a decomp: %v, want %v", found, tt.wantFound)
+			}
+			if len(tried) != tt.want-1 || int(triedWidth) != tt.want {
+				t.Errorf("tried the widths %v, last reported %d", tried, triedWidth)
+			}
+		})
+	}
+}
+
+func TestSearchWidthsParallel(t *testing.T) {
+	tests := []struct {
+		name     string
+		smallest int // the smallest width with a decomp, 0 if none
+		parallel int
+	}{
+		{"found at the lower bound", 2, 3},
+		{"found in between", 4, 2},
+		{"found in between, one at a time", 4, 1},
+		{"found at the upper bound", 6, 3},
+		{"none found", 0, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var triedWidth int64
+			result, optimal, err := searchWidthsParallel(context.Background(), 2, 6, tt.parallel, &triedWidth,
+				func(ctx context.Context, K int) (Decomp, logk.Algorithm, error) {
+					// smaller widths take longer, s.t. larger ones succeed first
+					select {
+					case <-time.After(time.Duration(10-K) * 5 * time.Millisecond):
+					case <-ctx.Done():
+						return Decomp{}, nil, nil
+					}
+					solver := &logk.LogKDecomp{K: K}
+					if tt.smallest > 0 && K >= tt.smallest {
+						return decompOfWidth(K), solver, nil
+					}
+					return Decomp{}, solver, nil
+				})
+			if err != nil {
+				t.Fatal(err)
+			}
+			decomp, width, winner := result.Decomp, result.K, result.Solver
+
+			if tt.smallest == 0 {
+				if !logk.IsEmptyDecomp(decomp) || winner != nil || width != 6 {
+					t.Errorf("got a decomp of width %d from %v, want none up to width 6", width, winner)
+				}
+				return
+			}
+			if !optimal {
+				t.Errorf("got a decomp of width %d, not known to be optimal", width)
+			}
+			if width != tt.smallest || decomp.CheckWidth() != tt.smallest {
+				t.Errorf("got width %d, with a decomp of width %d, want %d", width, decomp.CheckWidth(), tt.smallest)
+			}
+			if logK, ok := winner.(*logk.LogKDecomp); !ok || logK.K != tt.smallest {
+				t.Errorf("got the solver %v, want the one of width %d", winner, tt.smallest)
+			}
+		})
+	}
+}
+
+func TestSearchWidthsParallelCancelled(t *testing.T) {
+	// width 5 succeeds right away, while the smaller widths run until ctx gets cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var triedWidth int64
+	result, optimal, err := searchWidthsParallel(ctx, 2, 6, 4, &triedWidth,
+		func(ctx context.Context, K int) (Decomp, logk.Algorithm, error) {
+			if K == 5 {
+				time.AfterFunc(10*time.Millisecond, cancel)
+				return decompOfWidth(K), &logk.LogKDecomp{K: K}, nil
+			}
+			<-ctx.Done()
+			return Decomp{}, nil, nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	decomp, width, winner := result.Decomp, result.K, result.Solver
+
+	if logk.IsEmptyDecomp(decomp) || width != 5 || decomp.CheckWidth() != 5 {
+		t.Fatalf("got width %d, with the decomp %v, want the one of width 5", width, decomp)
+	}
+	if logK, ok := winner.(*logk.LogKDecomp); !ok || logK.K != 5 {
+		t.Errorf("got the solver %v, want the one of width 5", winner)
+	}
+	if optimal {
+		t.Errorf("got the decomp of width 5 as optimal, though the smaller widths never finished")
+	}
+}
+
+func TestSearchWidthsParallelError(t *testing.T) {
+	// width 3 fails with an error, which stops the searches of all other widths
+	failure := errors.New("failure")
+	var triedWidth int64
+	var stopped int64
+	_, _, err := searchWidthsParallel(context.Background(), 2, 6, 4, &triedWidth,
+		func(ctx context.Context, K int) (Decomp, logk.Algorithm, error) {
+			if K == 3 {
+				return Decomp{}, nil, failure
+			}
+			<-ctx.Done()
+			atomic.AddInt64(&stopped, 1)
+			return Decomp{}, nil, nil
+		})
+
+	if err != failure {
+		t.Errorf("got the error %v, want %v", err, failure)
+	}
+	if stopped != 3 {
+		t.Errorf("%d of the 3 other searches stopped before the call returned", stopped)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"math"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+const epsilon = 1e-9
+
+// fractionalCover computes the weight of an optimal fractional edge cover of the bag, using the given
+// edges. Instead of the covering LP itself, this solves its dual (a packing LP with the same optimum),
+// as the origin is a feasible starting point for the simplex method there:
+//
+//	max Σ y_v  s.t.  Σ_{v ∈ e} y_v ≤ 1 for each edge e, y ≥ 0
+func fractionalCover(bag []int, edges []Edge) float64 {
+	index := make(map[int]int)
+	for i, v := range bag {
+		index[v] = i
+	}
+
+	// one row per edge touching the bag, columns for the vertices, the slacks and the right hand side
+	var tableau [][]float64
+	for _, e := range edges {
+		row := make([]float64, len(bag))
+		touches := false
+		for _, v := range e.Vertices {
+			if i, ok := index[v]; ok {
+				row[i] = 1
+				touches = true
+			}
+		}
+		if touches {
+			tableau = append(tableau, row)
+		}
+	}
+
+	m, n := len(tableau), len(bag)
+	width := n + m + 1
+	basis := make([]int, m)
+	for i := range tableau {
+		row := make([]float64, width)
+		copy(row, tableau[i])
+		row[n+i] = 1
+		row[width-1] = 1
+		tableau[i] = row
+		basis[i] = n + i
+	}
+	objective := make([]float64, width)
+	for j := 0; j < n; j++ {
+		objective[j] = -1
+	}
+
+	for {
+		// Bland's rule: the first improving column enters, which guarantees termination
+		enter := -1
+		for j := 0; j < width-1; j++ {
+			if objective[j] < -epsilon {
+				enter = j
+				break
+			}
+		}
+		if enter < 0 {
+			break
+		}
+
+		leave := -1
+		best := math.Inf(1)
+		for i := 0; i < m; i++ {
+			if tableau[i][enter] <= epsilon {
+				continue
+			}
+			ratio := tableau[i][width-1] / tableau[i][enter]
+			if ratio < best-epsilon || (ratio < best+epsilon && basis[i] < basis[leave]) {
+				best = ratio
+				leave = i
+			}
+		}
+		if leave < 0 { // unbounded, only happens if some vertex of the bag isn't covered by any edge
+			return math.Inf(1)
+		}
+
+		pivot := tableau[leave][enter]
+		for j := range tableau[leave] {
+			tableau[leave][j] /= pivot
+		}
+		for i := 0; i < m; i++ {
+			if i != leave {
+				eliminate(tableau[i], tableau[leave], enter)
+			}
+		}
+		eliminate(objective, tableau[leave], enter)
+		basis[leave] = enter
+	}
+
+	return objective[width-1]
+}
+
+// eliminate subtracts a multiple of the pivot row, s.t. row becomes zero in the given column
+func eliminate(row, pivot []float64, column int) {
+	factor := row[column]
+	if factor == 0 {
+		return
+	}
+	for j := range row {
+		row[j] -= factor * pivot[j]
+	}
+}
+
+// fractionalWidth returns the largest weight of an optimal fractional edge cover of any bag in the
+// decomp, using all edges of the graph
+func fractionalWidth(decomp Decomp, graph Graph) float64 {
+	output := 0.0
+
+	current := []lib.Node{decomp.Root}
+	for len(current) > 0 {
+		var children []lib.Node
+		for _, n := range current {
+			output = math.Max(output, fractionalCover(n.Bag, graph.Edges.Slice()))
+			children = append(children, n.Children...)
+		}
+		current = children
+	}
+
+	return output
+}
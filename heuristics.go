@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+	"github.com/cem-okulmus/log-k-decomp/logk"
+)
+
+// heuristic is an ordering of the edges, used to find separators faster
+type heuristic struct {
+	name  string
+	order func(edges lib.Edges) lib.Edges
+	score func(edges lib.Edges) map[int]int // the weight each edge is ordered by, nil if there is none
+}
+
+// heuristics lists the available orderings, by the number used to select them on the command line
+var heuristics = map[int]heuristic{
+	1: libHeuristic(1, degreeScore),
+	2: libHeuristic(2, maxSepScore),
+	3: libHeuristic(3, nil),
+	4: libHeuristic(4, edgeDegreeScore),
+}
+
+// libHeuristic wraps one of the orderings offered by logk.ApplyHeuristic, with the weights it orders by
+func libHeuristic(n int, score func(edges lib.Edges) map[int]int) heuristic {
+	name, _ := logk.HeuristicName(n)
+	order, _ := logk.HeuristicOrder(n)
+	return heuristic{name: name, order: order, score: score}
+}
+
+// randomHeuristic shuffles the edges, seeded to make runs reproducible
+func randomHeuristic(seed int64) heuristic {
+	return heuristic{
+		name: fmt.Sprintf("random ordering (seed %d)", seed),
+		order: func(edges lib.Edges) lib.Edges {
+			output := append([]lib.Edge{}, edges.Slice()...)
+			r := rand.New(rand.NewSource(seed))
+			r.Shuffle(len(output), func(i, j int) { output[i], output[j] = output[j], output[i] })
+			return lib.NewEdges(output)
+		},
+	}
+}
+
+// degreeScore weighs each edge by the sum of the degrees of its vertices, like lib.GetDegreeOrder
+func degreeScore(edges lib.Edges) map[int]int {
+	degree := make(map[int]int)
+	for _, e := range edges.Slice() {
+		for _, v := range e.Vertices {
+			degree[v]++
+		}
+	}
+
+	output := make(map[int]int)
+	for _, e := range edges.Slice() {
+		for _, v := range e.Vertices {
+			output[e.Name] += degree[v] - 1
+		}
+	}
+	return output
+}
+
+// edgeDegreeScore weighs each edge by the number of edges it intersects, like lib.GetEdgeDegreeOrder
+func edgeDegreeScore(edges lib.Edges) map[int]int {
+	output := make(map[int]int)
+	for _, e := range edges.Slice() {
+		for _, o := range edges.Slice() {
+			if len(lib.Inter(e.Vertices, o.Vertices)) > 0 {
+				output[e.Name]++
+			}
+		}
+	}
+	return output
+}
+
+// maxSepScore weighs each edge by how much removing it lengthens the shortest paths in the primal graph,
+// with disconnected paths weighing more than any lengthening, like lib.GetMaxSepOrder
+func maxSepScore(edges lib.Edges) map[int]int {
+	vertices := edges.Vertices()
+	sepWeight := len(vertices) * len(vertices)
+	initial := primalDistances(vertices, edges.Slice())
+
+	output := make(map[int]int)
+	for i, e := range edges.Slice() {
+		without := append(append([]lib.Edge{}, edges.Slice()[:i]...), edges.Slice()[i+1:]...)
+		distances := primalDistances(vertices, without)
+
+		for pair, old := range initial {
+			if d, ok := distances[pair]; !ok {
+				output[e.Name] += sepWeight
+			} else {
+				output[e.Name] += d - old
+			}
+		}
+	}
+	return output
+}
+
+// primalDistances computes the distances between all connected pairs of vertices in the primal graph
+func primalDistances(vertices []int, edges []lib.Edge) map[[2]int]int {
+	neighbours := make(map[int][]int)
+	for _, e := range edges {
+		for _, v := range e.Vertices {
+			neighbours[v] = append(neighbours[v], e.Vertices...)
+		}
+	}
+
+	output := make(map[[2]int]int)
+	for _, source := range vertices {
+		dist := map[int]int{source: 0}
+		queue := []int{source}
+		for len(queue) > 0 {
+			v := queue[0]
+			queue = queue[1:]
+			for _, w := range neighbours[v] {
+				if _, ok := dist[w]; !ok {
+					dist[w] = dist[v] + 1
+					queue = append(queue, w)
+				}
+			}
+		}
+		for v, d := range dist {
+			if v != source {
+				output[[2]int{source, v}] = d
+			}
+		}
+	}
+	return output
+}
+
+// parseHeuristics reads a comma-separated list of heuristics, the first one being the primary ordering.
+// The seed is used by the random ordering.
+func parseHeuristics(list string, seed int64) ([]heuristic, error) {
+	var output []heuristic
+
+	for _, s := range strings.Split(list, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("invalid heuristic %q", s)
+		}
+		if n == 0 {
+			continue // no ordering
+		}
+		if n == 5 {
+			output = append(output, randomHeuristic(seed))
+			continue
+		}
+		h, ok := heuristics[n]
+		if !ok {
+			return nil, fmt.Errorf("unknown heuristic %d", n)
+		}
+		output = append(output, h)
+	}
+
+	return output, nil
+}
+
+// heuristicsName describes a sequence of heuristics
+func heuristicsName(hs []heuristic) string {
+	var names []string
+	for _, h := range hs {
+		names = append(names, h.name)
+	}
+	return strings.Join(names, ", then ")
+}
+
+// applyHeuristics orders the edges by the first heuristic, breaking ties using the following ones
+func applyHeuristics(edges lib.Edges, hs []heuristic) lib.Edges {
+	if len(hs) == 1 {
+		return hs[0].order(edges)
+	}
+
+	// the keys to sort by for each heuristic, orderings without weights contribute their positions
+	keys := make([]map[int]int, len(hs))
+	for i, h := range hs {
+		keys[i] = make(map[int]int)
+		if h.score != nil {
+			for name, score := range h.score(edges) {
+				keys[i][name] = -score // higher weights come first
+			}
+			continue
+		}
+		ordered := h.order(lib.NewEdges(append([]lib.Edge{}, edges.Slice()...)))
+		for pos, e := range ordered.Slice() {
+			keys[i][e.Name] = pos
+		}
+	}
+
+	output := append([]lib.Edge{}, edges.Slice()...)
+	sort.SliceStable(output, func(a, b int) bool {
+		for i := range keys {
+			keyA, keyB := keys[i][output[a].Name], keys[i][output[b].Name]
+			if keyA != keyB {
+				return keyA < keyB
+			}
+		}
+		return false
+	})
+
+	return lib.NewEdges(output)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+	"github.com/cem-okulmus/log-k-decomp/logk"
+)
+
+// The hinges of a hinge tree are decomposed independently of each other, but lib.Hingetree only exposes
+// DecompHinge, which decomposes them one after another. Hence DecompHinge is run twice: first with
+// hingeRecorder, to learn all hinges, then with hingeReplay, to merge the decomps computed concurrently.
+
+// hingeRecorder collects the hinges DecompHinge asks for, answering each with the trivial decomp, s.t.
+// every hinge of the tree gets visited
+type hingeRecorder struct {
+	hinges []Graph
+}
+
+func (r *hingeRecorder) Name() string { return "HingeRecorder" }
+
+func (r *hingeRecorder) FindDecomp() Decomp { return Decomp{} }
+
+func (r *hingeRecorder) FindDecompGraph(G Graph) Decomp {
+	r.hinges = append(r.hinges, G)
+	return Decomp{Graph: G, Root: lib.Node{Bag: G.Vertices(), Cover: G.Edges}}
+}
+
+func (r *hingeRecorder) SetWidth(K int) {}
+
+// hingeReplay answers DecompHinge with the decomps computed beforehand, the empty decomp for failed hinges
+type hingeReplay struct {
+	decomps map[string]Decomp
+}
+
+func (r hingeReplay) Name() string { return "HingeReplay" }
+
+func (r hingeReplay) FindDecomp() Decomp { return Decomp{} }
+
+func (r hingeReplay) FindDecompGraph(G Graph) Decomp {
+	decomp := r.decomps[hingeKey(G)]
+	if logk.IsEmptyDecomp(decomp) {
+		return Decomp{} // DecompHinge only recognises the zero value as failure
+	}
+	return decomp
+}
+
+func (r hingeReplay) SetWidth(K int) {}
+
+// hingeSolver hands the hinges DecompHinge asks for to a solver, keeping the first error of its searches,
+// which DecompHinge can't report. Once there is one, all further hinges fail right away.
+type hingeSolver struct {
+	logk.Algorithm
+	err error
+}
+
+func (h *hingeSolver) FindDecompGraph(G Graph) Decomp {
+	if h.err != nil {
+		return Decomp{}
+	}
+
+	decomp, err := findDecompGraph(h.Algorithm, G)
+	if err != nil {
+		h.err = err
+	}
+	if logk.IsEmptyDecomp(decomp) {
+		return Decomp{} // DecompHinge only recognises the zero value as failure
+	}
+	return decomp
+}
+
+// decompHinge computes the decomp of g via hinget.DecompHinge, returning the errors of solver
+func decompHinge(hinget lib.Hingetree, solver logk.Algorithm, g Graph) (Decomp, error) {
+	hinges := hingeSolver{Algorithm: solver}
+	decomp := hinget.DecompHinge(&hinges, g)
+	if hinges.err != nil {
+		return Decomp{}, hinges.err
+	}
+
+	return decomp, nil
+}
+
+// hingeKey identifies a hinge by the names of its edges, regardless of their order
+func hingeKey(g Graph) string {
+	names := make([]int, 0, g.Edges.Len())
+	for _, e := range g.Edges.Slice() {
+		names = append(names, e.Name)
+	}
+	sort.Ints(names)
+
+	return fmt.Sprint(names)
+}
+
+// decompHingeParallel computes the same decomp as hinget.DecompHinge, but decomposes up to parallel hinges
+// at once, each with its own solver created by newSolver. Once any hinge has no decomp, neither has the
+// graph, so the context given to the remaining solvers gets cancelled, as it does after an error.
+func decompHingeParallel(ctx context.Context, hinget lib.Hingetree, g Graph, parallel int,
+	newSolver func(ctx context.Context) logk.Algorithm) (Decomp, error) {
+	var recorder hingeRecorder
+	hinget.DecompHinge(&recorder, g)
+
+	ctxHinges, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	decomps := make([]Decomp, len(recorder.hinges))
+	errs := make([]error, len(recorder.hinges))
+	slots := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+
+	for i := range recorder.hinges {
+		i := i
+		slots <- struct{}{}
+		if ctxHinges.Err() != nil {
+			<-slots
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			decomps[i], errs[i] = findDecompGraph(newSolver(ctxHinges), recorder.hinges[i])
+			if errs[i] != nil || logk.IsEmptyDecomp(decomps[i]) {
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// the searches cancelled above, once some hinge failed, report just that
+	for i := range errs {
+		if errs[i] != nil && (ctx.Err() != nil || !errors.Is(errs[i], logk.ErrCancelled)) {
+			return Decomp{}, errs[i]
+		}
+	}
+
+	replay := hingeReplay{decomps: make(map[string]Decomp)}
+	for i := range recorder.hinges {
+		replay.decomps[hingeKey(recorder.hinges[i])] = decomps[i]
+	}
+
+	return hinget.DecompHinge(replay, g), nil
+}
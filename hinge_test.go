@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHingeWidthSearches(t *testing.T) {
+	// four 3x4 grids chained by single edges, each a hinge. Searched as a whole, the exact search doesn't
+	// finish within the timeout, while it takes well below a second one hinge at a time.
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"exact", []string{"-exact"}, "Exact width:  2\n"},
+		{"exact, hinges in parallel", []string{"-exact", "-hingeparallel", "2"}, "Exact width:  2\n"},
+		{"exact, widths in parallel", []string{"-exact", "-exactparallel", "2"}, "Exact width:  2\n"},
+		{"approx", []string{"-approx", "30"}, "Approximated width:  2 (proven optimal)\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"-graph", "testdata/hingechain.hg", "-logk", "-h", "-timeout", "30"}, tt.args...)
+			stdout, stderr, code := runMain(t, args...)
+			if code != 0 {
+				t.Fatalf("got exit code %d:\n%s%s", code, stdout, stderr)
+			}
+			if !strings.Contains(stdout, tt.want) || !strings.Contains(stdout, "Correct:  true\n") {
+				t.Errorf("want a correct decomp and %q, got:\n%s", tt.want, stdout)
+			}
+		})
+	}
+}
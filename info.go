@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cem-okulmus/log-k-decomp/logk"
+)
+
+// printGraphInfo reports basic metrics of a graph, to check an input before decomposing it
+func printGraphInfo(w io.Writer, g Graph) {
+	maxArity := 0
+	for _, e := range g.Edges.Slice() {
+		if len(e.Vertices) > maxArity {
+			maxArity = len(e.Vertices)
+		}
+	}
+
+	fmt.Fprintln(w, "Edges: ", g.Edges.Len())
+	fmt.Fprintln(w, "Vertices: ", len(g.Vertices()))
+	if len(g.Special) > 0 {
+		fmt.Fprintln(w, "Special edges: ", len(g.Special))
+	}
+	fmt.Fprintln(w, "Maximal arity: ", maxArity)
+	if duplicates := findDuplicates(g, nil); duplicates.count() > 0 || len(duplicates.loops) > 0 {
+		fmt.Fprintln(w, "Duplicate edges: ", duplicates.count())
+		fmt.Fprintln(w, "Edges repeating a vertex: ", len(duplicates.loops))
+	}
+	fmt.Fprintln(w, "BIP: ", g.GetBIP())
+	fmt.Fprintln(w, "Connected components: ", len(getConnectedComponents(g)))
+}
+
+// printRoot reports the root node of the decomp, along with the separators the root call of the search
+// settled on, if the algorithm keeps track of them
+func printRoot(w io.Writer, decomp Decomp, solver logk.Algorithm) {
+	if logk.IsEmptyDecomp(decomp) {
+		fmt.Fprintln(w, "Root: none, as no decomposition was found")
+	} else {
+		fmt.Fprintln(w, "Root bag: ", printVertices(decomp.Root.Bag))
+		fmt.Fprintln(w, "Root cover: ", printEdges(decomp.Root.Cover))
+	}
+
+	reporter, ok := solver.(logk.RootReporter)
+	if !ok {
+		return
+	}
+	child, parent, ok := reporter.RootSeparator()
+	if !ok {
+		fmt.Fprintln(w, "Balanced separator of the root call: none")
+		return
+	}
+	fmt.Fprintln(w, "Balanced separator of the root call: ", printEdges(child))
+	if parent.Len() > 0 {
+		fmt.Fprintln(w, "Parent separator of the root call: ", printEdges(parent))
+	}
+}
@@ -0,0 +1,279 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// readInput reads the contents of the input file at path, where "-" stands for stdin.
+// Gzip-compressed input, recognised by its suffix or header, is decompressed transparently.
+func readInput(path string) ([]byte, error) {
+	input, err := openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+
+	return ioutil.ReadAll(input)
+}
+
+// openInput opens the input file at path for reading, just like readInput, without reading it
+func openInput(path string) (io.ReadCloser, error) {
+	file := os.Stdin
+	if path != "-" {
+		var err error
+		if file, err = os.Open(path); err != nil {
+			return nil, err
+		}
+	}
+
+	buffered := bufio.NewReader(file)
+	header, _ := buffered.Peek(2)
+	if !strings.HasSuffix(path, ".gz") && !isGzip(header) {
+		return &inputFile{Reader: buffered, path: path, file: file}, nil
+	}
+
+	reader, err := gzip.NewReader(buffered)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("could not decompress %s: %v", path, err)
+	}
+
+	return &inputFile{Reader: reader, path: path, file: file, gzip: reader}, nil
+}
+
+// inputFile is an input file opened by openInput, possibly decompressed on the fly
+type inputFile struct {
+	io.Reader
+	path string
+	file *os.File
+	gzip *gzip.Reader // nil if the input isn't compressed
+}
+
+func (f *inputFile) Read(p []byte) (int, error) {
+	n, err := f.Reader.Read(p)
+	if err != nil && err != io.EOF && f.gzip != nil {
+		err = fmt.Errorf("could not decompress %s: %v", f.path, err)
+	}
+	return n, err
+}
+
+// Close closes the input file, unless it is stdin
+func (f *inputFile) Close() error {
+	if f.gzip != nil {
+		f.gzip.Close()
+	}
+	if f.file == os.Stdin {
+		return nil
+	}
+	return f.file.Close()
+}
+
+// isGzip checks for the magic header of gzip-compressed data
+func isGzip(dat []byte) bool {
+	return len(dat) >= 2 && dat[0] == 0x1f && dat[1] == 0x8b
+}
+
+// readGraph reads the input graph at path in the named input format. Graphs in the PACE format are read
+// line by line, to avoid holding large inputs in memory twice, while the other formats need the whole input.
+func readGraph(format string, path string) (Graph, error) {
+	if format != "pace" {
+		dat, err := readInput(path)
+		if err != nil {
+			return Graph{}, err
+		}
+		return parseGraph(format, string(dat))
+	}
+
+	input, err := openInput(path)
+	if err != nil {
+		return Graph{}, err
+	}
+	defer input.Close()
+
+	return readPACE(input)
+}
+
+// graphFormats maps the names accepted by -format to the parsers of the respective input formats
+var graphFormats = map[string]func(data string) (Graph, error){
+	"default": parseDefault,
+	"pace":    parsePACE,
+	"dimacs":  parseDIMACS,
+}
+
+// formatNames lists the supported input formats, for usage and error messages
+func formatNames() string {
+	var names []string
+	for name := range graphFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// parseGraph reads a graph in the named input format
+func parseGraph(format string, data string) (Graph, error) {
+	inputNames = nil // named by lib, unless read by readPACE
+	parse, ok := graphFormats[format]
+	if !ok {
+		return Graph{}, fmt.Errorf("unknown input format %q, supported are: %s", format, formatNames())
+	}
+	return parse(data)
+}
+
+// parseDefault reads a graph in the HyperBench format. Input consisting of nothing but comments is the
+// empty graph, which the parser of lib rejects.
+func parseDefault(data string) (graph Graph, err error) {
+	if isBlank(data) {
+		return Graph{}, nil
+	}
+	defer recoverParse(data, &err)
+
+	graph, _ = lib.GetGraph(data)
+	return graph, nil
+}
+
+// isBlank checks if data contains nothing but whitespace and comments of the HyperBench format
+func isBlank(data string) bool {
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) > 0 && !strings.HasPrefix(line, "%") && !strings.HasPrefix(line, "//") {
+			return false
+		}
+	}
+	return true
+}
+
+// parsePACE reads a graph in the PACE 2019 format
+func parsePACE(data string) (Graph, error) {
+	return readPACE(strings.NewReader(data))
+}
+
+// parseError reports malformed input, pointing at the offending line if it is known
+type parseError struct {
+	Line   int    // counted from 1, or 0 if unknown
+	Column int    // counted from 1, or 0 if unknown
+	Text   string // the offending line
+	Msg    string
+}
+
+func (e *parseError) Error() string {
+	switch {
+	case e.Line == 0:
+		return e.Msg
+	case e.Column == 0:
+		return fmt.Sprintf("line %d: %s\n\t%s", e.Line, e.Msg, e.Text)
+	default:
+		return fmt.Sprintf("line %d, column %d: %s\n\t%s", e.Line, e.Column, e.Msg, e.Text)
+	}
+}
+
+// sourcePosition matches the position the parsers of lib prefix their errors with
+var sourcePosition = regexp.MustCompile(`^<source>:(\d+):(\d+): (.*)$`)
+
+// recoverParse turns the panics the parsers of lib use to signal malformed input into a parseError, to be
+// deferred by functions calling them
+func recoverParse(data string, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	msg := strings.TrimSpace(fmt.Sprint(r))
+	match := sourcePosition.FindStringSubmatch(msg)
+	if match == nil {
+		*err = &parseError{Msg: msg}
+		return
+	}
+
+	line, _ := strconv.Atoi(match[1])
+	column, _ := strconv.Atoi(match[2])
+	output := &parseError{Line: line, Column: column, Msg: match[3]}
+	if lines := strings.Split(data, "\n"); line > 0 && line <= len(lines) {
+		output.Text = strings.TrimRight(lines[line-1], "\r")
+	} else {
+		output.Line = 0 // e.g. empty input
+	}
+	*err = output
+}
+
+// parseDIMACS reads a hypergraph in the DIMACS-like edge format, i.e. a problem line
+// "p edge <vertices> <edges>" followed by one line "e <v1> <v2> ..." per edge, with comment lines
+// starting with "c". Just like for the PACE format, edges are named E1, E2, ... and vertices V<n>.
+func parseDIMACS(data string) (Graph, error) {
+	var buffer strings.Builder
+	numVertices, numEdges, declaredEdges := -1, 0, 0
+
+	for i, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] == "c" {
+			continue
+		}
+
+		switch fields[0] {
+		case "p":
+			if numVertices >= 0 {
+				return Graph{}, fmt.Errorf("line %d: repeated problem line", i+1)
+			}
+			if len(fields) != 4 || fields[1] != "edge" {
+				return Graph{}, fmt.Errorf("line %d: expected \"p edge <vertices> <edges>\"", i+1)
+			}
+			var errV, errE error
+			numVertices, errV = strconv.Atoi(fields[2])
+			declaredEdges, errE = strconv.Atoi(fields[3])
+			if errV != nil || errE != nil || numVertices < 0 || declaredEdges < 0 {
+				return Graph{}, fmt.Errorf("line %d: invalid problem line", i+1)
+			}
+		case "e":
+			if numVertices < 0 {
+				return Graph{}, fmt.Errorf("line %d: edge before the problem line", i+1)
+			}
+			if len(fields) < 2 {
+				return Graph{}, fmt.Errorf("line %d: edge without vertices", i+1)
+			}
+			numEdges++
+			if numEdges > 1 {
+				buffer.WriteString(",\n")
+			}
+			buffer.WriteString("E" + strconv.Itoa(numEdges) + "(")
+			for j, f := range fields[1:] {
+				v, err := strconv.Atoi(f)
+				if err != nil || v < 1 || v > numVertices {
+					return Graph{}, fmt.Errorf("line %d: invalid vertex %q", i+1, f)
+				}
+				if j > 0 {
+					buffer.WriteString(",")
+				}
+				buffer.WriteString("V" + strconv.Itoa(v))
+			}
+			buffer.WriteString(")")
+		default:
+			return Graph{}, fmt.Errorf("line %d: unexpected %q", i+1, fields[0])
+		}
+	}
+
+	if numVertices < 0 {
+		return Graph{}, errors.New("missing problem line")
+	}
+	if numEdges != declaredEdges {
+		return Graph{}, fmt.Errorf("found %d edges, but the problem line declares %d", numEdges, declaredEdges)
+	}
+
+	if numEdges == 0 {
+		return Graph{}, nil
+	}
+
+	graph, _ := lib.GetGraph(buffer.String() + ".")
+	return graph, nil
+}
@@ -0,0 +1,1156 @@
+package logk
+
+// Parallel Algorithm for computing HD with log-depth recursion depth
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"runtime"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// LogKDecomp implements a parallel log-depth HD algorithm
+type LogKDecomp struct {
+	Graph           lib.Graph
+	K               int
+	Cache           Cache // stores the separators known to fail, a lib.Cache if nil
+	positive        positiveCache
+	BalFactor       int
+	BalPolicy       BalPolicy       // if set, determines the balance factor of each recursive call instead of BalFactor
+	MostBalanced    int             // if > 1, size of the window of child separators to pick the most balanced one from
+	FullBalCheck    bool            // check the balancedness of child separators via FullBalancedCheck, not lib.BalancedCheckFast
+	NoPositiveCache bool            // turns off the caching of subproblems known to have a decomp
+	MaxWorkers      int             // if > 0, limits the number of recursive calls running concurrently
+	Deterministic   bool            // search sequentially, so the same input always produces the same decomp
+	GHD             bool            // compute generalized hypertree decomps, i.e. without the special condition
+	DepthLimit      int             // if > 0, the search fails with ErrDepthExceeded beyond this recursion depth
+	SubTimeout      time.Duration   // if > 0, abandons recursive calls running longer, so failures are inconclusive
+	MaxCandidates   int             // if > 0, each call gives up after trying this many separators, so failures are inconclusive
+	SearchSplit     int             // if > 0, the number of generators each separator search is split into, GOMAXPROCS otherwise
+	Weights         map[int]float64 // if set, K bounds the summed weights of each cover, weights must be at least 1
+	Decisions       *DecisionLog    // if set, records every separator tried by the recursive calls
+	Replay          *DecisionLog    // if set, the separators accepted in this log are tried first, to reconstruct its decomp
+	EdgeName        EdgeNamer       // if set, names the edges in Decisions and Replay, lib's names otherwise
+	replay          map[replayKey][]Decision
+	restricted      *restriction          // set while FindDecompWithAllowed restricts the edges allowed in covers
+	restrictions    map[uint64]*lib.Cache // the separators known to fail under each restriction, by its allowed edges
+	workers         chan struct{}
+	maxDepth        int32     // maximal recursion depth reached during the last search
+	abandoned       int32     // set once a recursive call of the last search was abandoned or gave up
+	rootChild       lib.Edges // the balanced separator chosen by the root call of the last search
+	rootParent      lib.Edges // the parent separator chosen above it by the root call, if any
+	onImprovement   func(width int, d lib.Decomp)
+	bestWidth       int              // the smallest width of a decomp found so far, 0 if none
+	collector       *decompCollector // set while FindDecompsUpTo keeps the root call searching
+	ctx             context.Context
+	stats           searchStats
+}
+
+// decompInt is used to keep track of returned decompositions during concurrent search
+type decompInt struct {
+	Decomp lib.Decomp
+	Int    int
+	Err    error
+}
+
+// SetWidth sets the current width parameter of the algorithm. Cached results are only dropped if the
+// new width might invalidate them: a subproblem without a decomp of width K has none of a smaller width
+// either, so negative results survive decreasing the width, while a decomp of width K is also one of any
+// larger width, so positive results survive increasing it.
+func (l *LogKDecomp) SetWidth(K int) {
+	if K > l.K {
+		l.negativeCache().Reset()
+		l.restrictions = nil
+	}
+	if K < l.K {
+		l.positive.Reset()
+	}
+
+	l.K = K
+}
+
+// ResetCaches drops all cached results, along with the smallest width reported via OnImprovement, s.t. the
+// next search starts from scratch, just like one by a new instance of the algorithm
+func (l *LogKDecomp) ResetCaches() {
+	l.negativeCache().Reset()
+	l.restrictions = nil
+	l.positive.Reset()
+	l.bestWidth = 0
+}
+
+// Name returns the name of the algorithm
+func (l *LogKDecomp) Name() string {
+	if l.GHD {
+		return "LogKDecomp (GHD)"
+	}
+	return "LogKDecomp"
+}
+
+// SetContext sets the context used to cancel the search, any running search stops once it is done
+func (l *LogKDecomp) SetContext(ctx context.Context) {
+	l.ctx = ctx
+}
+
+// OnImprovement registers a callback, called whenever a search finds a decomp of smaller width than all
+// those found before by the algorithm, e.g. while searching through widths in descending order. The
+// width passed is that of the decomp itself, which may be smaller than K.
+func (l *LogKDecomp) OnImprovement(f func(width int, d lib.Decomp)) {
+	l.onImprovement = f
+}
+
+// improved calls the callback registered via OnImprovement, if decomp has the smallest width so far
+func (l *LogKDecomp) improved(decomp lib.Decomp) {
+	if l.onImprovement == nil || IsEmptyDecomp(decomp) {
+		return
+	}
+	width := decomp.CheckWidth()
+	if l.bestWidth == 0 || width < l.bestWidth {
+		l.bestWidth = width
+		l.onImprovement(width, decomp)
+	}
+}
+
+// searchContext returns the context of the search, defaulting to one that is never cancelled
+func (l *LogKDecomp) searchContext() context.Context {
+	if l.ctx == nil {
+		return context.Background()
+	}
+	return l.ctx
+}
+
+// FindDecomp finds a decomp. It panics if an invariant of the algorithm is violated,
+// use FindDecompResult to have this reported as an error instead.
+func (l *LogKDecomp) FindDecomp() lib.Decomp {
+	decomp, err := l.search()
+	if err != nil {
+		log.Panicln(err)
+	}
+
+	return decomp
+}
+
+// FindDecompContext finds a decomp just like FindDecomp, but stops the search once ctx is done, returning
+// the empty decomp along with ErrCancelled or ErrTimeout. Errors of the search, e.g. a violated invariant,
+// are returned instead of causing a panic. The context set via SetContext is only replaced for the
+// duration of the call.
+func (l *LogKDecomp) FindDecompContext(ctx context.Context) (lib.Decomp, error) {
+	old := l.ctx
+	defer func() { l.ctx = old }()
+	l.ctx = ctx
+
+	decomp, err := l.search()
+	if err != nil {
+		return lib.Decomp{}, err
+	}
+
+	// a decomp completed just as the search got cancelled is dropped as well
+	switch ctx.Err() {
+	case context.Canceled:
+		return lib.Decomp{}, ErrCancelled
+	case context.DeadlineExceeded:
+		return lib.Decomp{}, ErrTimeout
+	}
+
+	return decomp, nil
+}
+
+// search runs the actual search on the graph of the algorithm
+func (l *LogKDecomp) search() (lib.Decomp, error) {
+	l.negativeCache().Init()
+	atomic.StoreInt32(&l.maxDepth, 0)
+	atomic.StoreInt32(&l.abandoned, 0)
+	l.rootChild, l.rootParent = lib.Edges{}, lib.Edges{}
+
+	l.replay = nil
+	if l.Replay != nil {
+		l.replay = l.Replay.accepted()
+	}
+
+	l.workers = nil
+	if l.MaxWorkers > 0 {
+		l.workers = make(chan struct{}, l.MaxWorkers)
+	}
+
+	logf(LogInfo, "%s: searching for a decomp of width %d\n", l.Name(), l.K)
+	allowed := l.Graph.Edges
+	if l.restricted != nil {
+		allowed = l.restricted.allowed
+	}
+	decomp, err := l.findDecomp(l.searchContext(), l.Graph, []int{}, allowed, 0)
+	switch {
+	case err != nil:
+		logf(LogError, "%s: search of width %d failed: %v\n", l.Name(), l.K, err)
+	case IsEmptyDecomp(decomp):
+		logf(LogInfo, "%s: found no decomp of width %d, after %d recursive calls\n", l.Name(), l.K,
+			atomic.LoadInt64(&l.stats.calls))
+	default:
+		logf(LogInfo, "%s: found a decomp of width %d, after %d recursive calls\n", l.Name(), l.K,
+			atomic.LoadInt64(&l.stats.calls))
+		l.improved(decomp)
+	}
+
+	return decomp, err
+}
+
+// FindDecompResult finds a decomp, and returns an error explaining why none was found otherwise
+func (l *LogKDecomp) FindDecompResult() (lib.Decomp, error) {
+	if l.K <= 0 {
+		return lib.Decomp{}, ErrInvalidWidth
+	}
+	if IsEmptyGraph(l.Graph) { // the trivial decomp, which the search would report as a failure
+		return lib.Decomp{Graph: l.Graph}, nil
+	}
+
+	decomp, err := l.search()
+	if err != nil {
+		return lib.Decomp{}, err
+	}
+
+	switch l.searchContext().Err() {
+	case context.Canceled:
+		return lib.Decomp{}, ErrCancelled
+	case context.DeadlineExceeded:
+		return lib.Decomp{}, ErrTimeout
+	}
+
+	if IsEmptyDecomp(decomp) && l.Incomplete() {
+		return decomp, ErrIncomplete
+	}
+	if IsEmptyDecomp(decomp) {
+		return decomp, ErrNoDecomposition
+	}
+
+	return decomp, nil
+}
+
+// RootSeparator returns the balanced separator chosen by the root call of the last search, and the parent
+// separator chosen above it, which is empty if the child could serve as the root. ok is false if the root
+// call found no decomp using a separator.
+func (l *LogKDecomp) RootSeparator() (child lib.Edges, parent lib.Edges, ok bool) {
+	return l.rootChild, l.rootParent, l.rootChild.Len() > 0
+}
+
+// Stats returns the statistics collected over all searches since the last call of ResetStats
+func (l *LogKDecomp) Stats() Stats {
+	output := l.stats.snapshot()
+	l.negativeCache().Init()
+	if sized, ok := l.Cache.(interface{ Len() int }); ok {
+		output.CacheSize = sized.Len()
+	}
+
+	return output
+}
+
+// ResetStats sets all counters of the collected statistics back to zero
+func (l *LogKDecomp) ResetStats() {
+	l.stats.reset()
+}
+
+// negativeCache returns the cache of separators known to fail, creating the default one if none is set
+func (l *LogKDecomp) negativeCache() Cache {
+	if l.Cache == nil {
+		l.Cache = &lib.Cache{}
+	}
+	return l.Cache
+}
+
+// checkNegative wraps the check of the negative cache, to keep track of the hits. A separator failing
+// with all edges allowed fails under any restriction as well.
+func (l *LogKDecomp) checkNegative(sep lib.Edges, comps []lib.Graph) bool {
+	if l.Cache.CheckNegative(sep, comps) || (l.restricted != nil && l.restricted.negative.CheckNegative(sep, comps)) {
+		atomic.AddInt64(&l.stats.negativeHits, 1)
+		return true
+	}
+	return false
+}
+
+// addNegative wraps the insertion into the negative cache, to keep track of the inserts. Once a
+// recursive call was abandoned, failures are no longer conclusive and thus not cached anymore. Failures
+// under a restriction only hold for the same allowed edges, so they are kept apart.
+func (l *LogKDecomp) addNegative(sep lib.Edges, comp lib.Graph) {
+	if atomic.LoadInt32(&l.abandoned) > 0 {
+		return
+	}
+	atomic.AddInt64(&l.stats.negativeInserts, 1)
+	if l.restricted != nil {
+		l.restricted.negative.AddNegative(sep, comp)
+		return
+	}
+	l.Cache.AddNegative(sep, comp)
+}
+
+// searchSplit returns the number of generators to split each separator search into
+func (l *LogKDecomp) searchSplit() int {
+	if l.Deterministic {
+		return 1
+	}
+	if l.SearchSplit > 0 {
+		return l.SearchSplit
+	}
+	return runtime.GOMAXPROCS(-1)
+}
+
+// getComponents computes the components of H w.r.t. sep. In deterministic mode, the components are
+// sorted, as their order would otherwise change from run to run.
+func (l *LogKDecomp) getComponents(H lib.Graph, sep lib.Edges) ([]lib.Graph, []lib.Edge) {
+	comps, _, isolatedEdges := H.GetComponents(sep)
+	l.sortComponents(comps)
+
+	return comps, isolatedEdges
+}
+
+// sortComponents sorts the components in deterministic mode, s.t. they are in the same order in every run
+func (l *LogKDecomp) sortComponents(comps []lib.Graph) {
+	if l.Deterministic {
+		sort.Slice(comps, func(i, j int) bool {
+			if comps[i].Len() != comps[j].Len() {
+				return comps[i].Len() < comps[j].Len()
+			}
+			return comps[i].Hash() < comps[j].Hash()
+		})
+	}
+}
+
+// componentsWithin computes the components of low w.r.t. sep, just like getComponents, given the components
+// comps of H w.r.t. sep, where low is a subgraph of H. Every component of low lies within one of H, so the
+// components of H inside low are components of low as well, and only those partly inside low are split up
+// anew. As the parent loop mostly deals with a low component covering most of H, this saves computing the
+// components of the child over and over again.
+func (l *LogKDecomp) componentsWithin(low lib.Graph, comps []lib.Graph, sep lib.Edges) []lib.Graph {
+	inLow := make(map[int]bool, low.Edges.Len())
+	for _, e := range low.Edges.Slice() {
+		inLow[e.Name] = true
+	}
+	specialsLow := make(map[uint64]int, len(low.Special)) // special edges are told apart by their vertices only
+	for i := range low.Special {
+		specialsLow[low.Special[i].Hash()]++
+	}
+
+	var output []lib.Graph
+	for _, comp := range comps {
+		var edges []lib.Edge
+		for _, e := range comp.Edges.Slice() {
+			if inLow[e.Name] {
+				edges = append(edges, e)
+			}
+		}
+		var specials []lib.Edges
+		for i := range comp.Special {
+			if hash := comp.Special[i].Hash(); specialsLow[hash] > 0 {
+				specialsLow[hash]--
+				specials = append(specials, comp.Special[i])
+			}
+		}
+
+		switch {
+		case len(edges) == 0 && len(specials) == 0:
+		case len(edges) == comp.Edges.Len() && len(specials) == len(comp.Special):
+			atomic.AddInt64(&l.stats.reusedComps, 1)
+			output = append(output, comp)
+		default:
+			atomic.AddInt64(&l.stats.splitComps, 1)
+			part := lib.Graph{Edges: lib.NewEdges(edges), Special: specials}
+			split, _, _ := part.GetComponents(sep)
+			output = append(output, split...)
+		}
+	}
+	l.sortComponents(output)
+
+	return output
+}
+
+// spawn runs f in a new goroutine, unless the pool of workers is saturated, in which case f is run
+// synchronously instead
+func (l *LogKDecomp) spawn(f func()) {
+	if l.Deterministic {
+		f()
+		return
+	}
+	if l.workers == nil {
+		go f()
+		return
+	}
+
+	select {
+	case l.workers <- struct{}{}:
+		go func() {
+			defer func() { <-l.workers }()
+			f()
+		}()
+	default:
+		f()
+	}
+}
+
+// MaxDepth returns the maximal recursion depth reached during the last search
+func (l *LogKDecomp) MaxDepth() int {
+	return int(atomic.LoadInt32(&l.maxDepth))
+}
+
+// updateDepth keeps track of the maximal recursion depth, safe for concurrent use
+func (l *LogKDecomp) updateDepth(recDepth int) {
+	for {
+		current := atomic.LoadInt32(&l.maxDepth)
+		if int32(recDepth) <= current || atomic.CompareAndSwapInt32(&l.maxDepth, current, int32(recDepth)) {
+			return
+		}
+	}
+}
+
+// maxComponentSize returns the size of the largest component of H w.r.t. to the separator sep
+func maxComponentSize(H lib.Graph, sep lib.Edges) int {
+	comps, _, _ := H.GetComponents(sep)
+
+	output := 0
+	for i := range comps {
+		if comps[i].Len() > output {
+			output = comps[i].Len()
+		}
+	}
+
+	return output
+}
+
+// cancellableCheck extends a predicate of the separator search to accept any separator once the context
+// is done. The search of lib can't be stopped from outside, so this makes it return right away, and the
+// caller, checking the context before using the separator, stops as well.
+type cancellableCheck struct {
+	pred lib.Predicate
+	ctx  context.Context
+}
+
+// Check tests the context first, which is far cheaper than the predicate
+func (c cancellableCheck) Check(H *lib.Graph, sep *lib.Edges, balancedFactor int) bool {
+	select {
+	case <-c.ctx.Done():
+		return true
+	default:
+		return c.pred.Check(H, sep, balancedFactor)
+	}
+}
+
+// cancellable returns the predicate to use in separator searches, s.t. they stop once ctx is done
+func cancellable(ctx context.Context, pred lib.Predicate) lib.Predicate {
+	if ctx.Done() == nil { // never cancelled
+		return pred
+	}
+	return cancellableCheck{pred: pred, ctx: ctx}
+}
+
+// childSearch returns an iterator over the balanced separators of H among the allowed edges.
+// If MostBalanced is set, a window of that many separators is collected and returned in order
+// of their largest resulting component, otherwise they are returned in the order they are found.
+func (l *LogKDecomp) childSearch(ctx context.Context, H lib.Graph, allowed lib.Edges, balFactor int) func() (lib.Edges, bool) {
+	genChild := lib.SplitCombin(allowed.Len(), l.K, l.searchSplit(), false)
+	parallelSearch := lib.ParallelSearch{H: &H, Edges: &allowed, BalFactor: balFactor, Generators: genChild, Result: []int{}, ExhaustedSearch: false}
+	var pred lib.Predicate = lib.BalancedCheckFast{}
+	if l.FullBalCheck {
+		pred = FullBalancedCheck{}
+	}
+	pred = cancellable(ctx, l.weighted(pred))
+
+	windowSize := 1
+	if l.MostBalanced > 1 {
+		windowSize = l.MostBalanced
+	}
+
+	var window []lib.Edges
+
+	return func() (lib.Edges, bool) {
+		if len(window) == 0 {
+			for len(window) < windowSize {
+				parallelSearch.FindNext(pred)
+				if parallelSearch.ExhaustedSearch {
+					break
+				}
+				window = append(window, lib.GetSubset(allowed, parallelSearch.Result))
+			}
+
+			if len(window) > 1 {
+				sizes := make(map[uint64]int, len(window))
+				for i := range window {
+					sizes[window[i].Hash()] = maxComponentSize(H, window[i])
+				}
+				sort.SliceStable(window, func(i, j int) bool {
+					return sizes[window[i].Hash()] < sizes[window[j].Hash()]
+				})
+			}
+		}
+
+		if len(window) == 0 {
+			return lib.Edges{}, false
+		}
+
+		next := window[0]
+		window = window[1:]
+
+		return next, true
+	}
+}
+
+// parentSearch returns an iterator over the parent separators among the allowed edges, satisfying pred
+func (l *LogKDecomp) parentSearch(ctx context.Context, H lib.Graph, allowedParent lib.Edges, balFactor int, pred lib.Predicate) func() (lib.Edges, bool) {
+	genParent := lib.SplitCombin(allowedParent.Len(), l.K, l.searchSplit(), false)
+	parentalSearch := lib.ParallelSearch{H: &H, Edges: &allowedParent, BalFactor: balFactor, Generators: genParent, Result: []int{}, ExhaustedSearch: false}
+
+	pred = cancellable(ctx, pred)
+
+	return func() (lib.Edges, bool) {
+		parentalSearch.FindNext(pred)
+		if parentalSearch.ExhaustedSearch {
+			return lib.Edges{}, false
+		}
+		return lib.GetSubset(allowedParent, parentalSearch.Result), true
+	}
+}
+
+// FindDecompGraph finds a decomp, for an explicit graph
+func (l *LogKDecomp) FindDecompGraph(Graph lib.Graph) lib.Decomp {
+	l.Graph = Graph
+	return l.FindDecomp()
+}
+
+// FindDecompGraphResult finds a decomp for an explicit graph just like FindDecompResult, reporting errors
+// instead of causing a panic. The graph of the algorithm is left unchanged.
+func (l *LogKDecomp) FindDecompGraphResult(Graph lib.Graph) (lib.Decomp, error) {
+	oldGraph := l.Graph
+	defer func() { l.Graph = oldGraph }()
+
+	l.Graph = Graph
+	return l.FindDecompResult()
+}
+
+// FindDecompGraphK finds a decomp of width k for an explicit graph. The graph and width of the algorithm
+// are left unchanged, even if the search panics. Changing the width affects the caches as in SetWidth.
+func (l *LogKDecomp) FindDecompGraphK(Graph lib.Graph, k int) lib.Decomp {
+	oldGraph, oldK := l.Graph, l.K
+	defer func() {
+		l.Graph = oldGraph
+		if l.K != oldK {
+			l.SetWidth(oldK)
+		}
+	}()
+
+	l.Graph = Graph
+	if l.K != k {
+		l.SetWidth(k)
+	}
+	return l.FindDecomp()
+}
+
+// FindDecompWithAllowed finds a decomp whose covers only use the allowed edges, e.g. to keep some relations
+// of a CSP out of the covers. The allowed edges have to be edges of the graph. Every edge still has to be
+// covered by some bag, so no decomp exists if a vertex lies on none of the allowed edges. Like FindDecomp,
+// it panics if an invariant of the algorithm is violated.
+func (l *LogKDecomp) FindDecompWithAllowed(allowed lib.Edges) lib.Decomp {
+	if !lib.Subset(l.Graph.Edges.Vertices(), allowed.Vertices()) {
+		return lib.Decomp{}
+	}
+
+	// separators failing with fewer edges may well succeed with all of them, so the negative results of a
+	// restricted search are kept apart, for later searches with the same allowed edges
+	negative, ok := l.restrictions[allowed.Hash()]
+	if !ok {
+		negative = &lib.Cache{}
+		negative.Init()
+		if l.restrictions == nil {
+			l.restrictions = make(map[uint64]*lib.Cache)
+		}
+		l.restrictions[allowed.Hash()] = negative
+	}
+	l.restricted = &restriction{allowed: allowed, negative: negative}
+	defer func() { l.restricted = nil }()
+
+	return l.FindDecomp()
+}
+
+// restriction holds the edges FindDecompWithAllowed allows in covers, along with the separators known to
+// fail under it
+type restriction struct {
+	allowed  lib.Edges
+	negative *lib.Cache
+}
+
+// coverAllowed checks if a base case may cover H by its own edges. Without a restriction by
+// FindDecompWithAllowed, the edges of a subproblem are always among its allowed edges.
+func (l *LogKDecomp) coverAllowed(H lib.Graph, allowedFull lib.Edges) bool {
+	if l.restricted == nil {
+		return true
+	}
+
+	allowed := make(map[int]bool, allowedFull.Len())
+	for _, e := range allowedFull.Slice() {
+		allowed[e.Name] = true
+	}
+	for _, e := range H.Edges.Slice() {
+		if !allowed[e.Name] {
+			return false
+		}
+	}
+	return true
+}
+
+// determine whether we have reached a (positive or negative) base case
+func (l *LogKDecomp) baseCaseCheck(H lib.Graph, allowedFull lib.Edges) bool {
+	lenE, lenSp, lenAE := H.Edges.Len(), len(H.Special), allowedFull.Len()
+	if lenSp == 0 && l.fitsWidth(H.Edges) && l.coverAllowed(H, allowedFull) {
+		return true
+	}
+	if lenE == 0 && lenSp == 1 {
+		return true
+	}
+	if lenE == 0 && lenSp > 1 {
+		return true
+	}
+	if lenAE == 0 && (lenE+lenSp) >= 0 {
+		return true
+	}
+
+	return false
+}
+
+func (l *LogKDecomp) baseCase(H lib.Graph, allowedFull lib.Edges) lib.Decomp {
+	// log.Printf("Base case reached. Number of Special Edges %d\n", len(Sp))
+	var output lib.Decomp
+	lenAE := allowedFull.Len()
+
+	// cover faiure cases
+	if H.Edges.Len() == 0 && len(H.Special) > 1 {
+		return lib.Decomp{}
+	}
+	if lenAE == 0 && (H.Len()) >= 0 {
+		return lib.Decomp{}
+	}
+
+	// construct a decomp in the remaining two
+	if len(H.Special) == 0 && l.fitsWidth(H.Edges) && l.coverAllowed(H, allowedFull) {
+		output = lib.Decomp{Graph: H, Root: lib.Node{Bag: H.Vertices(), Cover: H.Edges}}
+	}
+	if H.Edges.Len() == 0 && len(H.Special) == 1 {
+		sp1 := H.Special[0]
+		output = lib.Decomp{Graph: H,
+			Root: lib.Node{Bag: sp1.Vertices(), Cover: sp1}}
+
+	}
+
+	return output
+}
+
+// attachingSubtrees attaches subtreeBelow to the leaf of subtreeAbove covered by the connecting special edge.
+// It fails if there is no such leaf, which signals a bug in the composition of the subtrees.
+func attachingSubtrees(subtreeAbove lib.Node, subtreeBelow lib.Node, connecting lib.Edges) (lib.Node, error) {
+	// log.Println("Two Nodes enter: ", subtreeAbove, subtreeBelow)
+	// log.Println("Connecting: ", PrintVertices(connecting.Vertices))
+
+	//finding connecting leaf in parent
+	leaf := subtreeAbove.CombineNodes(subtreeBelow, connecting)
+
+	if leaf == nil {
+		var dump bytes.Buffer
+		fmt.Fprintln(&dump, "Connection ", lib.PrintVertices(connecting.Vertices()))
+		fmt.Fprintln(&dump, "subtreeAbove ", subtreeAbove)
+
+		return lib.Node{}, invariantError("subtreeAbove doesn't contain connecting node!", &dump)
+	}
+
+	return *leaf, nil
+}
+
+// invariantError produces an error for a violated invariant, with a dump of the current search state
+func invariantError(reason string, dump *bytes.Buffer) error {
+	return fmt.Errorf("%w: %s\n%s", ErrInvariantViolated, reason, dump.String())
+}
+
+// Incomplete reports whether the last search abandoned any recursive call due to the SubTimeout, gave up
+// on one due to MaxCandidates, or rejected a parent whose subtrees could not be attached. In these cases, a
+// failure to find a decomp might be a false negative.
+func (l *LogKDecomp) Incomplete() bool {
+	return atomic.LoadInt32(&l.abandoned) > 0
+}
+
+// findDecomp runs a recursive call, under its own deadline if a SubTimeout is set
+func (l *LogKDecomp) findDecomp(ctx context.Context, H lib.Graph, Conn []int, allowedFull lib.Edges, recDepth int) (lib.Decomp, error) {
+	if l.SubTimeout <= 0 {
+		return l.findDecompBranch(ctx, H, Conn, allowedFull, recDepth)
+	}
+
+	ctxSub, cancelSub := context.WithTimeout(ctx, l.SubTimeout)
+	defer cancelSub()
+
+	decomp, err := l.findDecompBranch(ctxSub, H, Conn, allowedFull, recDepth)
+	if err == nil && IsEmptyDecomp(decomp) && ctxSub.Err() != nil && ctx.Err() == nil {
+		// only this branch ran out of time, so its siblings may continue
+		atomic.StoreInt32(&l.abandoned, 1)
+		atomic.AddInt64(&l.stats.abandoned, 1)
+	}
+
+	return decomp, err
+}
+
+// outOfCandidates counts another separator tried by a recursive call, and checks if it has to give up
+func (l *LogKDecomp) outOfCandidates(tried *int) bool {
+	*tried++
+	if l.MaxCandidates <= 0 || *tried <= l.MaxCandidates {
+		return false
+	}
+
+	atomic.StoreInt32(&l.abandoned, 1)
+	atomic.AddInt64(&l.stats.truncated, 1)
+	return true
+}
+
+func (l *LogKDecomp) findDecompBranch(ctx context.Context, H lib.Graph, Conn []int, allowedFull lib.Edges, recDepth int) (lib.Decomp, error) {
+	if ctx.Err() != nil { // search got cancelled
+		return lib.Decomp{}, nil
+	}
+	atomic.AddInt64(&l.stats.calls, 1)
+
+	recDepth = recDepth + 1 // increase the recursive depth
+	l.updateDepth(recDepth)
+	if l.DepthLimit > 0 && recDepth > l.DepthLimit {
+		return lib.Decomp{}, fmt.Errorf("%w: reached depth %d of at most %d", ErrDepthExceeded, recDepth, l.DepthLimit)
+	}
+
+	if logEnabled(LogTrace) {
+		logf(LogTrace, "Current SubGraph: %v\nCurrent Allowed Edges: %v\nConn: %v\n", H, allowedFull,
+			lib.PrintVertices(Conn))
+	}
+
+	if !subsetSorted(Conn, H.Vertices()) {
+		var dump bytes.Buffer
+		fmt.Fprintln(&dump, "Current SubGraph, ", H)
+		fmt.Fprintln(&dump, "Conn ", lib.PrintVertices(Conn))
+
+		return lib.Decomp{}, invariantError("Conn invariant violated.", &dump)
+	}
+
+	// Base Case
+	if l.baseCaseCheck(H, allowedFull) {
+		return l.baseCase(H, allowedFull), nil
+	}
+
+	//all vertices within (H ∪ Sp)
+	VerticesH := append(H.Vertices())
+
+	// Only edges touching H are of use, which also holds for all subproblems, as they lie within H. So the
+	// recursive calls get the filtered edges, s.t. they don't have to refilter all of allowedFull.
+	allowed := allowedFull
+	if !l.GHD { // covers of a GHD may use any edge, not only those touching H
+		allowed = filterTouching(allowedFull, VerticesH)
+	}
+
+	// check cache for previously found decomps of the same subproblem, unless more than one is wanted
+	if !l.NoPositiveCache && (recDepth > 1 || l.collector == nil) {
+		if decomp, ok := l.positive.Check(H, Conn, allowed); ok {
+			atomic.AddInt64(&l.stats.positiveHits, 1)
+			return decomp, nil
+		}
+	}
+
+	// Set up iterator for child
+	balFactor := l.balFactor(recDepth, H)
+	nextChild := l.childSearch(ctx, H, allowed, balFactor)
+	replayed := l.replayed(H, allowed)
+	if len(replayed) > 0 {
+		nextChild = l.replayChildren(replayed, allowed, nextChild)
+	}
+
+	// the number of separators tried so far, every one but the last having failed
+	tried := 0
+
+	// checks all possibles nodes in H, together with PARENT loops, it covers all parent-child pairings
+CHILD:
+	for childλ, found := nextChild(); found; childλ, found = nextChild() {
+		if ctx.Err() != nil {
+			return lib.Decomp{}, nil
+		}
+		if l.outOfCandidates(&tried) {
+			return lib.Decomp{}, nil
+		}
+		atomic.AddInt64(&l.stats.children, 1)
+
+		compsε, _ := l.getComponents(H, childλ)
+
+		logf(LogTrace, "Balanced Child found, %v of H %v\n", childλ, H)
+
+		// Check if child is possible root
+		if subsetSorted(Conn, childλ.Vertices()) {
+			logf(LogDebug, "Child-Root cover chosen: %v of %v\n", childλ, H)
+			logf(LogTrace, "Comps of Child-Root: %v\n", compsε)
+
+			childχ := interSorted(childλ.Vertices(), VerticesH)
+
+			// check cache for previous encounters
+			if l.checkNegative(childλ, compsε) {
+				logf(LogTrace, "Skipping a child sep %v\n", lib.PrintVertices(childχ))
+				continue CHILD
+			}
+
+			var subtrees []lib.Node
+			for y := range compsε {
+				VCompε := compsε[y].Vertices()
+				Connγ := interSorted(VCompε, childχ)
+
+				decomp, err := l.findDecomp(ctx, compsε[y], Connγ, allowed, recDepth)
+				if err != nil {
+					return lib.Decomp{}, err
+				}
+				if IsEmptyDecomp(decomp) {
+					if ctx.Err() != nil { // don't cache results of a cancelled search
+						return lib.Decomp{}, nil
+					}
+					if logEnabled(LogTrace) {
+						logf(LogTrace, "Rejecting child-root %v\nCurrent SubGraph: %v\nCurrent Allowed Edges: %v\nConn: %v\n",
+							childλ, H, allowed, lib.PrintVertices(Conn))
+					}
+					l.addNegative(childλ, compsε[y])
+					l.record(H, allowed, recDepth, childλ, lib.Edges{}, false)
+					continue CHILD
+				}
+
+				logf(LogTrace, "Produced Decomp w Child-Root: %+v\n", decomp)
+				subtrees = append(subtrees, decomp.Root)
+			}
+
+			root := lib.Node{Bag: childχ, Cover: childλ, Children: subtrees}
+			output := lib.Decomp{Graph: H, Root: root}
+			l.record(H, allowed, recDepth, childλ, lib.Edges{}, true)
+			if !l.NoPositiveCache {
+				atomic.AddInt64(&l.stats.positiveInserts, 1)
+				l.positive.Add(H, allowed, output)
+			}
+			if recDepth == 1 {
+				if !l.collector.add(output) { // another decomp with the same root
+					continue CHILD
+				}
+				l.rootChild, l.rootParent = childλ, lib.Edges{}
+				if l.collector.more() {
+					continue CHILD
+				}
+			}
+			return output, nil
+		}
+
+		// Set up iterator for parent
+		allowedParent := filterTouching(allowed, unionSorted(Conn, childλ.Vertices()))
+		predPar := l.weighted(parentCheck{Conn: Conn, Child: childλ.Vertices()})
+		nextParent := l.parentSearch(ctx, H, allowedParent, balFactor, predPar)
+		if len(replayed) > 0 {
+			nextParent = l.replayParents(replayed, H, childλ, allowedParent, predPar, balFactor, nextParent)
+		}
+		// parentFound := false
+	PARENT:
+		for parentλ, found := nextParent(); found; parentλ, found = nextParent() {
+			if ctx.Err() != nil {
+				return lib.Decomp{}, nil
+			}
+			if l.outOfCandidates(&tried) {
+				return lib.Decomp{}, nil
+			}
+			atomic.AddInt64(&l.stats.parents, 1)
+
+			logf(LogTrace, "Looking at parent %v\n", parentλ)
+			compsπ, isolatedEdges := l.getComponents(H, parentλ)
+			logf(LogTrace, "Parent components %v\n", compsπ)
+
+			foundLow := false
+			var compLowIndex int
+			var compLow lib.Graph
+
+			balancednessLimit := (((H.Len()) * (balFactor - 1)) / balFactor)
+
+			// Check if parent is un-balanced
+			for i := range compsπ {
+				if compsπ[i].Len() > balancednessLimit {
+					foundLow = true
+					compLowIndex = i //keep track of the index for composing comp_up later
+					compLow = compsπ[i]
+				}
+			}
+			if !foundLow {
+				var dump bytes.Buffer
+				fmt.Fprintln(&dump, "Current SubGraph, ", H)
+				fmt.Fprintln(&dump, "Conn ", lib.PrintVertices(Conn))
+
+				fmt.Fprintf(&dump, "Current Allowed Edges: %v\n", allowed)
+				fmt.Fprintf(&dump, "Current Allowed Edges in Parent Search: %v\n", allowedParent)
+
+				fmt.Fprintln(&dump, "Child ", childλ)
+				fmt.Fprintln(&dump, "Comps of child ", compsε)
+				fmt.Fprintln(&dump, "parent ", parentλ)
+
+				fmt.Fprintln(&dump, "Comps of p: ")
+				for i := range compsπ {
+					fmt.Fprintln(&dump, "Component: ", compsπ[i], " Len: ", compsπ[i].Len())
+
+				}
+
+				return lib.Decomp{}, invariantError("the parallel search didn't actually find a valid parent", &dump)
+			}
+
+			vertCompLow := compLow.Vertices()
+			childχ := interSorted(childλ.Vertices(), vertCompLow)
+
+			// determine which componenents of child are inside comp_low, from those in H found above
+			compsε := l.componentsWithin(compLow, compsε, childλ)
+
+			//omitting the check for balancedness as it's guaranteed to still be conserved at this point
+
+			// check chache for previous encounters
+			if l.checkNegative(childλ, compsε) {
+				logf(LogTrace, "Skipping a child sep %v\n", lib.PrintVertices(childχ))
+				continue PARENT
+			}
+
+			if logEnabled(LogDebug) {
+				logf(LogDebug, "Parent Found: %v (%s)\n", parentλ, lib.PrintVertices(parentλ.Vertices()))
+				logf(LogTrace, "Comp low: %v Vertices of comp_low %s\n", compLow, lib.PrintVertices(vertCompLow))
+				logf(LogDebug, "Child chosen: %v (%s) for H %v\n", childλ, lib.PrintVertices(childχ), H)
+				logf(LogTrace, "Comps of Child: %v\n", compsε)
+			}
+			// parentFound = true
+
+			//Computing subcomponents of Child
+
+			// 1. CREATE GOROUTINES
+			// ---------------------
+
+			// used to stop any remaining goroutines, should this parent be abandoned
+			ctxPar, cancelPar := context.WithCancel(ctx)
+
+			//Computing upper component in parallel
+
+			chUp := make(chan decompInt, 1) // buffered, so abandoned senders never block
+
+			var compUp lib.Graph
+			var decompUp lib.Decomp
+			var specialChild lib.Edges
+			tempEdgeSlice := []lib.Edge{}
+			tempSpecialSlice := []lib.Edges{}
+
+			tempEdgeSlice = append(tempEdgeSlice, isolatedEdges...)
+			for i := range compsπ {
+				if i != compLowIndex {
+					tempEdgeSlice = append(tempEdgeSlice, compsπ[i].Edges.Slice()...)
+					tempSpecialSlice = append(tempSpecialSlice, compsπ[i].Special...)
+				}
+			}
+
+			// specialChild = NewEdges([]Edge{Edge{Vertices: Inter(childχ, comp_up.Vertices())}})
+			specialChild = lib.NewEdges([]lib.Edge{{Vertices: childχ}})
+
+			// keep track of the number of goroutines that send their result back
+			numSenders := 0
+
+			// if no comps_p, other than comp_low, just use parent as is
+			if len(compsπ) == 1 {
+				compUp.Edges = parentλ
+
+				// adding new Special Edge to connect Child to comp_up
+				compUp.Special = append(compUp.Special, specialChild)
+
+				decompTemp := lib.Decomp{Graph: compUp, Root: lib.Node{Bag: interSorted(parentλ.Vertices(), VerticesH),
+					Cover: parentλ, Children: []lib.Node{{Bag: specialChild.Vertices(), Cover: childλ}}}}
+
+				numSenders++
+				go func(decomp lib.Decomp) {
+					chUp <- decompInt{Decomp: decomp}
+				}(decompTemp)
+
+			} else if len(tempEdgeSlice) > 0 { // otherwise compute decomp for comp_up
+
+				compUp.Edges = lib.NewEdges(tempEdgeSlice)
+				compUp.Special = tempSpecialSlice
+
+				// adding new Special Edge to connect Child to comp_up
+				compUp.Special = append(compUp.Special, specialChild)
+
+				logf(LogTrace, "Upper component: %v\n", compUp)
+
+				//Reducing the allowed edges, only needed to guarantee the special condition of HDs
+				allowedReduced := allowed
+				if !l.GHD {
+					allowedReduced = allowed.Diff(compLow.Edges)
+				}
+
+				numSenders++
+				l.spawn(func() {
+					var out decompInt
+					out.Decomp, out.Err = l.findDecomp(ctxPar, compUp, Conn, allowedReduced, recDepth)
+					chUp <- out
+				})
+
+			} else { // the other comps_p only have special edges, so use parent as is, with them as leaves
+				compUp.Edges = parentλ
+				compUp.Special = append(tempSpecialSlice, specialChild)
+
+				children := []lib.Node{{Bag: specialChild.Vertices(), Cover: childλ}}
+				for _, sp := range tempSpecialSlice {
+					children = append(children, lib.Node{Bag: sp.Vertices(), Cover: sp})
+				}
+				decompTemp := lib.Decomp{Graph: compUp, Root: lib.Node{Bag: interSorted(parentλ.Vertices(), VerticesH),
+					Cover: parentλ, Children: children}}
+
+				numSenders++
+				go func(decomp lib.Decomp) {
+					chUp <- decompInt{Decomp: decomp}
+				}(decompTemp)
+			}
+
+			// Parallel Recursive Calls:
+
+			ch := make(chan decompInt, len(compsε)) // buffered, so abandoned senders never block
+			var subtrees []lib.Node
+
+			numSenders = numSenders + len(compsε)
+
+			for x := range compsε {
+				Connχ := interSorted(compsε[x].Vertices(), childχ)
+
+				x := x
+				l.spawn(func() {
+					var out decompInt
+					out.Decomp, out.Err = l.findDecomp(ctxPar, compsε[x], Connχ, allowed, recDepth)
+					out.Int = x
+					ch <- out
+				})
+
+			}
+
+			// 2. WAIT ON GOROUTINES TO FINISH
+			// ---------------------
+
+			for i := 0; i < numSenders; i++ {
+				select {
+				case decompInt := <-ch:
+					if decompInt.Err != nil {
+						cancelPar()
+						return lib.Decomp{}, decompInt.Err
+					}
+
+					if IsEmptyDecomp(decompInt.Decomp) {
+						cancelPar() // stop the remaining recursive calls
+
+						// don't cache results of a cancelled search
+						if ctx.Err() != nil {
+							return lib.Decomp{}, nil
+						}
+
+						l.addNegative(childλ, compsε[decompInt.Int])
+						l.record(H, allowed, recDepth, childλ, parentλ, false)
+						logf(LogTrace, "Rejecting child %v\n", childλ)
+						continue PARENT
+					}
+
+					logf(LogTrace, "Produced Decomp: %+v\n", decompInt.Decomp)
+					subtrees = append(subtrees, decompInt.Decomp.Root)
+
+				case decompUpInt := <-chUp:
+					if decompUpInt.Err != nil {
+						cancelPar()
+						return lib.Decomp{}, decompUpInt.Err
+					}
+					decompUpChan := decompUpInt.Decomp
+
+					if IsEmptyDecomp(decompUpChan) {
+						cancelPar() // stop the remaining recursive calls
+						if ctx.Err() != nil {
+							return lib.Decomp{}, nil
+						}
+
+						// l.addNegative(childχ, comp_up, Sp)
+						l.record(H, allowed, recDepth, childλ, parentλ, false)
+						logf(LogTrace, "Rejecting comp_up %v of H %v\n", compUp, H)
+
+						continue PARENT
+					}
+
+					if !lib.Subset(Conn, decompUpChan.Root.Bag) {
+						cancelPar()
+
+						var dump bytes.Buffer
+						fmt.Fprintln(&dump, "Current SubGraph, ", H)
+						fmt.Fprintln(&dump, "Conn ", lib.PrintVertices(Conn))
+
+						fmt.Fprintf(&dump, "Current Allowed Edges: %v\n", allowed)
+						fmt.Fprintf(&dump, "Current Allowed Edges in Parent Search: %v\n", allowedParent)
+
+						fmt.Fprintln(&dump, "Child ", childλ, "  ", lib.PrintVertices(childχ))
+						fmt.Fprintln(&dump, "Comps of child ", compsε)
+						fmt.Fprintln(&dump, "parent ", parentλ, " Vertices(parent) ", lib.PrintVertices(parentλ.Vertices()))
+
+						fmt.Fprintln(&dump, "comp_up ", compUp, " V(comp_up) ", lib.PrintVertices(compUp.Vertices()))
+
+						fmt.Fprintln(&dump, "Decomp up:  ", decompUpChan)
+
+						fmt.Fprintln(&dump, "Comps of p", compsπ)
+
+						return lib.Decomp{}, invariantError("Conn not covered in parent, Wait, what?", &dump)
+					}
+
+					decompUp = decompUpChan
+
+				}
+
+			}
+			cancelPar() // all goroutines are done at this point
+
+			// 3. POST-PROCESSING (sequentially)
+			// ---------------------
+
+			// rearrange subtrees to form one that covers total of H
+			rootChild := lib.Node{Bag: childχ, Cover: childλ, Children: subtrees}
+
+			var finalRoot lib.Node
+			if len(tempEdgeSlice) > 0 || len(compsπ) > 1 {
+				var err error
+				finalRoot, err = attachingSubtrees(decompUp.Root, rootChild, specialChild)
+				if err != nil {
+					// reject just this parent, but the search can no longer prove that no decomp exists
+					logf(LogError, "Warning: rejecting parent %v of child %v: %v\n", parentλ, childλ, err)
+					atomic.StoreInt32(&l.abandoned, 1)
+					continue PARENT
+				}
+			} else {
+				finalRoot = rootChild
+			}
+
+			logf(LogTrace, "Produced Decomp: %v\n", finalRoot)
+			output := lib.Decomp{Graph: H, Root: finalRoot}
+			l.record(H, allowed, recDepth, childλ, parentλ, true)
+			if !l.NoPositiveCache {
+				atomic.AddInt64(&l.stats.positiveInserts, 1)
+				l.positive.Add(H, allowed, output)
+			}
+			if recDepth == 1 {
+				if !l.collector.add(output) { // another decomp with the same root
+					continue PARENT
+				}
+				l.rootChild, l.rootParent = childλ, parentλ
+				if l.collector.more() {
+					continue PARENT
+				}
+			}
+			return output, nil
+		}
+		// if parentFound {
+		// 	log.Println("Rejecting child ", childλ, " for H ", H)
+		// 	log.Printf("\nCurrent SubGraph: %v\n", H)
+		// 	log.Printf("Current Allowed Edges: %v\n", allowed)
+		// 	log.Println("Conn: ", PrintVertices(Conn), "\n\n")
+		// }
+
+	}
+
+	// exhausted search space
+	if recDepth == 1 && l.collector.found() {
+		return l.collector.decomps[len(l.collector.decomps)-1], nil
+	}
+	return lib.Decomp{}, nil
+}
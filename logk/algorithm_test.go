@@ -0,0 +1,252 @@
+package logk
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// bagsCover reports whether the vertices of e are contained in the bag of some node below n
+func bagsCover(n lib.Node, e lib.Edge) bool {
+	if lib.Subset(e.Vertices, n.Bag) {
+		return true
+	}
+	for _, c := range n.Children {
+		if bagsCover(c, e) {
+			return true
+		}
+	}
+	return false
+}
+
+// TestSpecialOnlyParentComponents covers a parent leaving, besides comp_low, a component made up of special
+// edges only, so there are no edges to build comp_up from. This used to deadlock while waiting on comp_up,
+// and then dropped the parent along with the special edge.
+func TestSpecialOnlyParentComponents(t *testing.T) {
+	// the recursive call for the chain e1 - e4 and the special edge {x, y}, connected to the rest via x.
+	// The parent p, from outside of the subgraph, splits off {x, y}, leaving no edges but the chain.
+	graph, _ := lib.GetGraph("e1(a,b), e2(b,c), e3(c,d), e4(d,e), p(x,a), q(x,y).")
+	edges := graph.Edges.Slice()
+	special := lib.NewEdges([]lib.Edge{{Vertices: edges[5].Vertices}})
+	H := lib.Graph{Edges: lib.NewEdges(edges[:4]), Special: []lib.Edges{special}}
+	allowed := lib.NewEdges(edges[:5])
+	conn := []int{edges[4].Vertices[0]}
+
+	for K := 1; K <= 3; K++ {
+		l := &LogKDecomp{Graph: H, K: K, BalFactor: 2, Deterministic: true}
+		l.negativeCache().Init()
+
+		done := make(chan struct{})
+		var decomp lib.Decomp
+		var err error
+		go func() {
+			defer close(done)
+			decomp, err = l.findDecomp(context.Background(), H, conn, allowed, 0)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("K = %d: the search deadlocked", K)
+		}
+
+		if err != nil {
+			t.Fatalf("K = %d: %v", K, err)
+		}
+		if IsEmptyDecomp(decomp) {
+			t.Fatalf("K = %d: found no decomp", K)
+		}
+		if !lib.Subset(conn, decomp.Root.Bag) {
+			t.Errorf("K = %d: the root doesn't cover the connecting vertices:\n%v", K, decomp)
+		}
+		for _, e := range append(H.Edges.Slice(), special.Slice()...) {
+			if !bagsCover(decomp.Root, e) {
+				t.Errorf("K = %d: no bag covers %v:\n%v", K, e, decomp)
+			}
+		}
+	}
+}
+
+// componentKeys describes each component by its edges, its special edges, and the vertices it shares with
+// sep, sorted s.t. the components of two calls can be compared regardless of their order
+func componentKeys(comps []lib.Graph, sep lib.Edges) []string {
+	var keys []string
+	for _, comp := range comps {
+		var names []int
+		for _, e := range comp.Edges.Slice() {
+			names = append(names, e.Name)
+		}
+		sort.Ints(names)
+		var specials []string
+		for _, sp := range comp.Special {
+			vertices := append([]int{}, sp.Vertices()...)
+			sort.Ints(vertices)
+			specials = append(specials, fmt.Sprint(vertices))
+		}
+		sort.Strings(specials)
+		conn := lib.Inter(comp.Vertices(), sep.Vertices())
+		sort.Ints(conn)
+
+		keys = append(keys, fmt.Sprintf("edges %v, special %v, conn %v", names, specials, conn))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TestComponentsWithin compares componentsWithin against computing the components of the low graph from
+// scratch, on random graphs with special edges, for low graphs that are components of the graph w.r.t. a
+// parent separator, as in the search, as well as arbitrary subgraphs
+func TestComponentsWithin(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	l := &LogKDecomp{Deterministic: true}
+
+	for i := 0; i < 300; i++ {
+		graph := randomGraph(r, 10, 6+r.Intn(8), 3)
+		H := lib.Graph{Edges: graph.Edges}
+		vertices := graph.Vertices()
+		for s := r.Intn(4); s > 0; s-- {
+			perm := r.Perm(len(vertices))[:2+r.Intn(2)]
+			special := make([]int, len(perm))
+			for j, p := range perm {
+				special[j] = vertices[p]
+			}
+			sort.Ints(special)
+			H.Special = append(H.Special, lib.NewEdges([]lib.Edge{{Vertices: special}}))
+		}
+
+		edges := H.Edges.Slice()
+		pick := func(n int) lib.Edges {
+			var picked []lib.Edge
+			for _, j := range r.Perm(len(edges))[:n] {
+				picked = append(picked, edges[j])
+			}
+			return lib.NewEdges(picked)
+		}
+		child, parent := pick(1+r.Intn(2)), pick(1+r.Intn(2))
+		comps, _, _ := H.GetComponents(child)
+
+		lows, _, _ := H.GetComponents(parent)
+		var subgraph lib.Graph
+		var subEdges []lib.Edge
+		for _, e := range edges {
+			if r.Intn(3) > 0 {
+				subEdges = append(subEdges, e)
+			}
+		}
+		subgraph.Edges = lib.NewEdges(subEdges)
+		for _, sp := range H.Special {
+			if r.Intn(2) > 0 {
+				subgraph.Special = append(subgraph.Special, sp)
+			}
+		}
+		lows = append(lows, H, subgraph)
+
+		for _, low := range lows {
+			want, _, _ := low.GetComponents(child)
+			got := l.componentsWithin(low, comps, child)
+
+			wantKeys := strings.Join(componentKeys(want, child), "\n")
+			if gotKeys := strings.Join(componentKeys(got, child), "\n"); gotKeys != wantKeys {
+				t.Fatalf("graph %v, child %v, low %v:\ngot\n%s\nwant\n%s", H, child, low, gotKeys, wantKeys)
+			}
+		}
+	}
+}
+
+// coversWithin reports whether the covers of all nodes below n consist of allowed edges only
+func coversWithin(n lib.Node, allowed map[int]bool) bool {
+	for _, e := range n.Cover.Slice() {
+		if !allowed[e.Name] {
+			return false
+		}
+	}
+	for _, c := range n.Children {
+		if !coversWithin(c, allowed) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestFindDecompWithAllowed(t *testing.T) {
+	graph := readGraph(t, "grid3x4.hg")
+	edges := graph.Edges.Slice()
+	r := rand.New(rand.NewSource(3))
+
+	found := 0
+	for i := 0; i < 20; i++ {
+		var allowed []lib.Edge
+		allowedNames := make(map[int]bool)
+		for _, e := range edges {
+			if r.Intn(4) > 0 {
+				allowed = append(allowed, e)
+				allowedNames[e.Name] = true
+			}
+		}
+
+		for K := 2; K <= 3; K++ {
+			l := &LogKDecomp{Graph: graph, K: K, BalFactor: 2}
+			decomp := l.FindDecompWithAllowed(lib.NewEdges(allowed))
+			if IsEmptyDecomp(decomp) {
+				continue
+			}
+			found++
+			if !decomp.Correct(graph) {
+				t.Errorf("K = %d, allowed %v: incorrect decomp %v", K, allowed, decomp)
+			}
+			if !coversWithin(decomp.Root, allowedNames) {
+				t.Errorf("K = %d, allowed %v: the decomp uses other edges: %v", K, allowed, decomp)
+			}
+		}
+	}
+	if found == 0 {
+		t.Error("found no decomp for any of the allowed edges")
+	}
+
+	// without any edge on the first vertex, the graph has no decomp using only the allowed edges
+	v := edges[0].Vertices[0]
+	var allowed []lib.Edge
+	for _, e := range edges {
+		if !lib.Subset([]int{v}, e.Vertices) {
+			allowed = append(allowed, e)
+		}
+	}
+	l := &LogKDecomp{Graph: graph, K: graph.Edges.Len(), BalFactor: 2}
+	if decomp := l.FindDecompWithAllowed(lib.NewEdges(allowed)); !IsEmptyDecomp(decomp) {
+		t.Errorf("found a decomp, though no allowed edge contains %d: %v", v, decomp)
+	}
+}
+
+func TestFindDecompWithAllowedKeepsNegatives(t *testing.T) {
+	graph := readGraph(t, "k5.hg")
+	restricted := lib.NewEdges(graph.Edges.Slice()[1:])
+	cache := &lib.Cache{}
+	l := &LogKDecomp{Graph: graph, K: 2, BalFactor: 2, Deterministic: true, Cache: cache}
+
+	// the separators failing with all edges allowed stay cached, while the restricted search keeps its own
+	if !IsEmptyDecomp(l.FindDecomp()) {
+		t.Fatal("found a decomp of width 2")
+	}
+	negatives := cache.Len()
+	if negatives == 0 {
+		t.Fatal("the failing search cached no separators")
+	}
+	if !IsEmptyDecomp(l.FindDecompWithAllowed(restricted)) {
+		t.Fatal("found a restricted decomp of width 2")
+	}
+	if got := cache.Len(); got != negatives {
+		t.Errorf("the restricted search changed the negative cache from %d to %d separators", negatives, got)
+	}
+
+	// nor must the failures under the restriction keep the search with all edges from succeeding
+	l.SetWidth(3)
+	l.FindDecompWithAllowed(restricted)
+	if decomp := l.FindDecomp(); IsEmptyDecomp(decomp) || !decomp.Correct(graph) {
+		t.Errorf("found no correct decomp of width 3 after the restricted search: %v", decomp)
+	}
+}
@@ -0,0 +1,103 @@
+package logk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+func TestAttachingSubtreesFailure(t *testing.T) {
+	graph, _ := lib.GetGraph("e0(a,b), e1(b,c), e2(c,d).")
+	e := graph.Edges.Slice()
+	special := lib.NewEdges([]lib.Edge{{Vertices: e[2].Vertices}})
+
+	// the subtree above has no leaf for the special edge, the subtree below gets attached nowhere
+	above := lib.Node{Bag: e[0].Vertices, Cover: lib.NewEdges(e[:1])}
+	below := lib.Node{Bag: e[2].Vertices, Cover: lib.NewEdges(e[2:])}
+	_, err := attachingSubtrees(above, below, special)
+
+	if !errors.Is(err, ErrInvariantViolated) {
+		t.Fatalf("got error %v, want %v", err, ErrInvariantViolated)
+	}
+	if !strings.Contains(err.Error(), "subtreeAbove") {
+		t.Errorf("want the subtrees dumped in the error, got %v", err)
+	}
+}
+
+// TestAttachingSubtreesFailureRejectsParent makes the decomps of comp_up miss the leaf for the child, by
+// poisoning the cached decomps, and checks that the parents in question are rejected, while the search
+// goes on to find another decomp
+func TestAttachingSubtreesFailureRejectsParent(t *testing.T) {
+	// the recursive call for the chain e1 - e6 with r hanging off a, and the special edge {x, y}, connected
+	// to the rest via x. Only p, from outside of the subgraph, covers x, and it isn't balanced on its own,
+	// so it has to serve as a parent, leaving r in comp_up.
+	graph, _ := lib.GetGraph("e1(a,b), e2(b,c), e3(c,d), e4(d,e), e5(e,f), e6(f,g), r(a,z), p(x,a), q(x,y).")
+	edges := graph.Edges.Slice()
+	special := lib.NewEdges([]lib.Edge{{Vertices: edges[8].Vertices}})
+	H := lib.Graph{Edges: lib.NewEdges(edges[:7]), Special: []lib.Edges{special}}
+	allowed := lib.NewEdges(edges[:8])
+	conn := []int{edges[7].Vertices[0]}
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	for K := 1; K <= 2; K++ {
+		l := &LogKDecomp{Graph: H, K: K, BalFactor: 2, Deterministic: true}
+		l.negativeCache().Init()
+		decomp, err := l.findDecomp(context.Background(), H, conn, allowed, 0)
+		if err != nil || IsEmptyDecomp(decomp) {
+			t.Fatalf("K = %d: found no decomp: %v", K, err)
+		}
+
+		// strip the leaves off the cached decomps of subgraphs with special edges, and drop the one of H
+		for key, decomp := range l.positive.cache {
+			if decomp.Graph.Edges.Len() == H.Edges.Len() {
+				delete(l.positive.cache, key)
+			} else if len(decomp.Graph.Special) > 0 {
+				decomp.Root = stripLeaves(decomp.Root)
+				l.positive.cache[key] = decomp
+			}
+		}
+
+		logs.Reset()
+		decomp, err = l.findDecomp(context.Background(), H, conn, allowed, 0)
+		if err != nil {
+			t.Fatalf("K = %d: %v", K, err)
+		}
+		if !l.Incomplete() || !strings.Contains(logs.String(), "subtreeAbove doesn't contain connecting node") {
+			t.Fatalf("K = %d: no parent got rejected, logged:\n%s", K, logs.String())
+		}
+
+		// other pairs of child and parent still lead to a decomp
+		if IsEmptyDecomp(decomp) {
+			t.Fatalf("K = %d: found no decomp", K)
+		}
+		if !lib.Subset(conn, decomp.Root.Bag) || ValidateConnectedness(decomp) != nil {
+			t.Errorf("K = %d: invalid decomp:\n%v", K, decomp)
+		}
+		for _, e := range append(H.Edges.Slice(), special.Slice()...) {
+			if !bagsCover(decomp.Root, e) {
+				t.Errorf("K = %d: no bag covers %v:\n%v", K, e, decomp)
+			}
+		}
+	}
+}
+
+// stripLeaves removes all leaves below n
+func stripLeaves(n lib.Node) lib.Node {
+	var children []lib.Node
+	for _, c := range n.Children {
+		if len(c.Children) > 0 {
+			children = append(children, stripLeaves(c))
+		}
+	}
+	n.Children = children
+	return n
+}
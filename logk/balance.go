@@ -0,0 +1,64 @@
+package logk
+
+import "github.com/cem-okulmus/BalancedGo/lib"
+
+// BalPolicy determines the balance factor used by a recursive call, given its recursion depth, which is 1
+// for the root call, and the size of its subgraph. Any factor of at least 2 keeps the search complete, but
+// only a constant one guarantees the logarithmic recursion depth.
+type BalPolicy func(depth int, size int) int
+
+// ConstantBalance is the policy using the same balance factor for every recursive call, just like BalFactor
+func ConstantBalance(factor int) BalPolicy {
+	return func(depth int, size int) int {
+		return factor
+	}
+}
+
+// DepthBalance is the policy starting with the given factor at the root call, and increasing it by step
+// with every level of the recursion, s.t. the separators near the leaves may be less balanced
+func DepthBalance(factor, step int) BalPolicy {
+	return func(depth int, size int) int {
+		return factor + (depth-1)*step
+	}
+}
+
+// balFactor returns the balance factor of a recursive call, at least 2
+func (l *LogKDecomp) balFactor(recDepth int, H lib.Graph) int {
+	if l.BalPolicy == nil {
+		return l.BalFactor
+	}
+	if factor := l.BalPolicy(recDepth, H.Len()); factor > 2 {
+		return factor
+	}
+	return 2
+}
+
+// FullBalancedCheck is the balancedness predicate of lib.BalancedCheck, spelled out in full: a separator is
+// balanced if each component it leaves has at most (balFactor-1)/balFactor of the edges and special edges
+// of H, and it doesn't consist of the vertices of a special edge. Unlike lib, which compares hashes of the
+// vertex sets for the latter, it compares the sets themselves, so no separator is rejected by a collision.
+// It may be slower than lib.BalancedCheckFast, but serves to rule out that the fast check misses any
+// balanced separator.
+type FullBalancedCheck struct{}
+
+// Check performs the needed computation to ensure whether sep is a balanced separator
+func (FullBalancedCheck) Check(H *lib.Graph, sep *lib.Edges, balFactor int) bool {
+	comps, _, _ := H.GetComponents(*sep)
+
+	balancednessLimit := (H.Len() * (balFactor - 1)) / balFactor
+	for i := range comps {
+		if comps[i].Len() > balancednessLimit {
+			return false
+		}
+	}
+
+	vertices := sep.Vertices()
+	for i := range H.Special {
+		special := H.Special[i].Vertices()
+		if len(special) == len(vertices) && subsetSorted(special, vertices) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,54 @@
+package logk
+
+import (
+	"testing"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// hashCollision finds two disjoint pairs of vertices with the same lib.IntHash
+func hashCollision() (sep []int, special []int, ok bool) {
+	seen := make(map[uint32][]int)
+	for a := 1; a < 2000; a++ {
+		for b := 1; b < a; b++ {
+			h := lib.IntHash([]int{a, b})
+			if other, found := seen[h]; found && !lib.Subset([]int{a}, other) && !lib.Subset([]int{b}, other) {
+				return []int{a, b}, other, true
+			}
+			seen[h] = []int{a, b}
+		}
+	}
+	return nil, nil, false
+}
+
+func TestBalancedChecksDiffer(t *testing.T) {
+	sepVertices, specialVertices, ok := hashCollision()
+	if !ok {
+		t.Skip("found no two vertex sets of equal hashes")
+	}
+
+	// the separator is balanced, leaving the special edge as the only component, but it hashes just like it
+	sep := lib.NewEdges([]lib.Edge{{Name: 1, Vertices: sepVertices}})
+	special := lib.NewEdges([]lib.Edge{{Vertices: specialVertices}})
+	H := lib.Graph{Edges: sep, Special: []lib.Edges{special}}
+
+	if (lib.BalancedCheckFast{}).Check(&H, &sep, 2) {
+		t.Errorf("the fast check accepts %v, despite its hash equal to the one of the special edge %v",
+			sepVertices, specialVertices)
+	}
+	if !(FullBalancedCheck{}).Check(&H, &sep, 2) {
+		t.Errorf("the full check rejects %v, which differs from the special edge %v", sepVertices, specialVertices)
+	}
+
+	// both reject the special edge itself as a separator, and agree once the separator is unbalanced
+	if (FullBalancedCheck{}).Check(&H, &special, 2) {
+		t.Errorf("the full check accepts the special edge %v", specialVertices)
+	}
+	other := lib.NewEdges([]lib.Edge{{Name: 2, Vertices: []int{specialVertices[0]}}})
+	H.Edges = lib.NewEdges(append(sep.Slice(), lib.Edge{Name: 3, Vertices: []int{sepVertices[0], specialVertices[0]}}))
+	for _, pred := range []lib.Predicate{lib.BalancedCheckFast{}, FullBalancedCheck{}} {
+		if pred.Check(&H, &other, 2) {
+			t.Errorf("%T accepts the unbalanced separator %v", pred, other)
+		}
+	}
+}
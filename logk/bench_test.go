@@ -0,0 +1,70 @@
+package logk
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+)
+
+// BenchmarkFindDecompParallel searches for a decomp of the 4×5 grid, of hypertree width 3, with GOMAXPROCS
+// set to each power of two up to the number of CPUs, and to the number of CPUs itself. The time per search
+// is expected to drop as processors are added. Settings beyond the number of CPUs are left out, since the
+// concurrent searches of separators then only compete for the same cores. Each iteration uses a new
+// instance of the algorithm, s.t. no search profits from the caches of an earlier one:
+//
+//	go test -run NONE -bench FindDecompParallel ./logk
+func BenchmarkFindDecompParallel(b *testing.B) {
+	graph := readGraph(b, "grid4x5.hg")
+	const width = 3
+
+	var settings []int
+	for procs := 1; procs < runtime.NumCPU(); procs *= 2 {
+		settings = append(settings, procs)
+	}
+	settings = append(settings, runtime.NumCPU())
+
+	for _, procs := range settings {
+		b.Run(fmt.Sprintf("procs=%d", procs), func(b *testing.B) {
+			defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+			for i := 0; i < b.N; i++ {
+				l := &LogKDecomp{Graph: graph, K: width, BalFactor: 2}
+				if decomp := l.FindDecomp(); IsEmptyDecomp(decomp) {
+					b.Fatalf("found no decomp of width %d", width)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkFindDecompMaxWorkers searches for a decomp of the 4×5 grid, of hypertree width 3, with the
+// recursive calls running concurrently bounded by MaxWorkers, from a single worker up to the number of
+// CPUs, and without any bound. Fewer workers run more of the recursive calls synchronously, trading
+// the time per search for fewer goroutines alive at once:
+//
+//	go test -run NONE -bench FindDecompMaxWorkers ./logk
+func BenchmarkFindDecompMaxWorkers(b *testing.B) {
+	graph := readGraph(b, "grid4x5.hg")
+	const width = 3
+
+	var settings []int
+	for workers := 1; workers < runtime.NumCPU(); workers *= 2 {
+		settings = append(settings, workers)
+	}
+	settings = append(settings, runtime.NumCPU(), 0)
+
+	for _, workers := range settings {
+		name := fmt.Sprintf("workers=%d", workers)
+		if workers == 0 {
+			name = "workers=unbounded"
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				l := &LogKDecomp{Graph: graph, K: width, BalFactor: 2, MaxWorkers: workers}
+				if decomp := l.FindDecomp(); IsEmptyDecomp(decomp) {
+					b.Fatalf("found no decomp of width %d", width)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package logk
+
+// cache.go implements the caches for subproblems that are known to have a decomposition, or known to
+// have none
+
+import (
+	"sync"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// Cache stores the separators known to fail for some component, so they can be skipped when the same
+// component is encountered again. lib.Cache is the default implementation.
+type Cache interface {
+	Init()
+	Reset()
+	AddNegative(sep lib.Edges, comp lib.Graph)
+	CheckNegative(sep lib.Edges, comps []lib.Graph) bool
+}
+
+// NoCache is a Cache that never stores anything, e.g. to measure the benefit of caching
+type NoCache struct{}
+
+// Init does nothing
+func (NoCache) Init() {}
+
+// Reset does nothing
+func (NoCache) Reset() {}
+
+// AddNegative does nothing
+func (NoCache) AddNegative(sep lib.Edges, comp lib.Graph) {}
+
+// CheckNegative never finds a separator
+func (NoCache) CheckNegative(sep lib.Edges, comps []lib.Graph) bool { return false }
+
+// Len always returns zero
+func (NoCache) Len() int { return 0 }
+
+// positiveKey identifies a subproblem by its subgraph and the edges allowed to cover it
+type positiveKey struct {
+	graph   uint64
+	allowed uint64
+}
+
+// positiveCache stores the decompositions found for subproblems, so they can be
+// reused when the same subproblem is reached again via a different separator
+type positiveCache struct {
+	cache    map[positiveKey]lib.Decomp
+	cacheMux sync.RWMutex
+}
+
+// copyNode produces a deep copy of a node, as some operations change the children of a node in place
+func copyNode(n lib.Node) lib.Node {
+	output := lib.Node{Bag: append([]int{}, n.Bag...), Cover: n.Cover}
+
+	for i := range n.Children {
+		output.Children = append(output.Children, copyNode(n.Children[i]))
+	}
+
+	return output
+}
+
+// Reset will throw out all saved cache entries
+func (c *positiveCache) Reset() {
+	c.cacheMux.Lock()
+	defer c.cacheMux.Unlock()
+
+	c.cache = nil
+}
+
+// Add stores the decomp found for the subgraph H, using the edges in allowed
+func (c *positiveCache) Add(H lib.Graph, allowed lib.Edges, decomp lib.Decomp) {
+	c.cacheMux.Lock()
+	defer c.cacheMux.Unlock()
+
+	if c.cache == nil {
+		c.cache = make(map[positiveKey]lib.Decomp)
+	}
+
+	decomp.Root = copyNode(decomp.Root)
+	c.cache[positiveKey{graph: H.Hash(), allowed: allowed.Hash()}] = decomp
+}
+
+// Check looks for a stored decomp of the subgraph H, using the edges in allowed.
+// A stored decomp is only returned if its root covers the connecting vertices Conn.
+func (c *positiveCache) Check(H lib.Graph, Conn []int, allowed lib.Edges) (lib.Decomp, bool) {
+	c.cacheMux.RLock()
+	defer c.cacheMux.RUnlock()
+
+	decomp, ok := c.cache[positiveKey{graph: H.Hash(), allowed: allowed.Hash()}]
+	if !ok || !lib.Subset(Conn, decomp.Root.Bag) {
+		return lib.Decomp{}, false
+	}
+
+	decomp.Root = copyNode(decomp.Root)
+	return decomp, true
+}
@@ -0,0 +1,43 @@
+package logk
+
+import "github.com/cem-okulmus/BalancedGo/lib"
+
+// ComponentResult is the decomp of a single connected component, or the error of its search
+type ComponentResult struct {
+	Graph  lib.Graph
+	Decomp lib.Decomp
+	Err    error
+}
+
+// FindDecompComponents decomposes each connected component of the graph separately, sending the result of
+// each component on the returned channel as soon as it is found. The channel is closed after the last
+// component, or right after the first error, since the graph then has no decomp either way. Cancelling
+// the context of the algorithm stops the search, even if nobody reads from the channel anymore.
+// The algorithm must not be used otherwise until the channel is closed.
+func (l *LogKDecomp) FindDecompComponents() <-chan ComponentResult {
+	comps, _, _ := l.Graph.GetComponents(lib.Edges{})
+	output := make(chan ComponentResult)
+
+	go func() {
+		defer close(output)
+
+		graph := l.Graph
+		defer func() { l.Graph = graph }()
+
+		for _, comp := range comps {
+			l.Graph = comp
+			decomp, err := l.FindDecompResult()
+
+			select {
+			case output <- ComponentResult{Graph: comp, Decomp: decomp, Err: err}:
+			case <-l.searchContext().Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return output
+}
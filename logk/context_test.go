@@ -0,0 +1,43 @@
+package logk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFindDecompContextCancel(t *testing.T) {
+	l := &LogKDecomp{Graph: gridGraph(6, 6), K: 3, BalFactor: 2} // takes far longer than the test
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var cancelled time.Time
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancelled = time.Now()
+		cancel()
+	}()
+
+	decomp, err := l.FindDecompContext(ctx)
+	if !errors.Is(err, ErrCancelled) {
+		t.Fatalf("got error %v, want %v", err, ErrCancelled)
+	}
+	if !IsEmptyDecomp(decomp) {
+		t.Errorf("got a decomp from the cancelled search: %v", decomp)
+	}
+	if d := time.Since(cancelled); d > time.Second {
+		t.Errorf("search took %v to return after being cancelled", d)
+	}
+}
+
+func TestFindDecompContextError(t *testing.T) {
+	l := &LogKDecomp{Graph: gridGraph(3, 8), K: 2, BalFactor: 2, DepthLimit: 1}
+
+	decomp, err := l.FindDecompContext(context.Background())
+	if !errors.Is(err, ErrDepthExceeded) {
+		t.Fatalf("got error %v, want %v", err, ErrDepthExceeded)
+	}
+	if !IsEmptyDecomp(decomp) {
+		t.Errorf("got a decomp despite the error: %v", decomp)
+	}
+}
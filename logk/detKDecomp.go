@@ -1,8 +1,7 @@
-package main
+package logk
 
 import (
 	"log"
-	"reflect"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
 )
@@ -18,7 +17,9 @@ type DetKDecomp struct {
 
 // SetWidth sets the current width parameter of the algorithm
 func (d *DetKDecomp) SetWidth(K int) {
-	d.cache.Reset() // reset the cache as the new width might invalidate any old results
+	if K > d.K { // negative results at width K still hold for any smaller width
+		d.cache.Reset()
+	}
 
 	d.K = K
 }
@@ -181,7 +182,7 @@ OUTER:
 
 					for i := range comps {
 						decomp := d.findDecomp(comps[i], bag, recDepth)
-						if reflect.DeepEqual(decomp, lib.Decomp{}) {
+						if IsEmptyDecomp(decomp) {
 
 							d.cache.AddNegative(sepActual, comps[i])
 							// log.Printf("detK REJECTING %v: couldn't decompose %v  \n",
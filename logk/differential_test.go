@@ -0,0 +1,169 @@
+package logk
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// contains reports whether v is one of the vertices
+func contains(vertices []int, v int) bool {
+	for _, u := range vertices {
+		if u == v {
+			return true
+		}
+	}
+	return false
+}
+
+// randomGraph returns a connected graph of m edges over at most n vertices, each edge of two up to arity
+// vertices, with no two edges on the same vertices. There need to be at least m such edges, e.g. for
+// m <= n(n-1)/2.
+func randomGraph(r *rand.Rand, n, m, arity int) lib.Graph {
+	var edges []string
+	seen := make(map[string]bool)
+	used := []int{0}
+
+	for len(edges) < m {
+		vertices := []int{used[r.Intn(len(used))]} // shared with the edges so far, keeping the graph connected
+		for size := 2 + r.Intn(arity-1); len(vertices) < size; {
+			v := r.Intn(n)
+			if !contains(vertices, v) {
+				vertices = append(vertices, v)
+			}
+		}
+
+		names := make([]string, n)
+		for _, v := range vertices {
+			names[v] = fmt.Sprintf("v%d", v)
+		}
+		key := strings.Join(names, " ")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		var list []string
+		for _, v := range vertices {
+			list = append(list, names[v])
+			if !contains(used, v) {
+				used = append(used, v)
+			}
+		}
+		edges = append(edges, fmt.Sprintf("e%d(%s)", len(edges), strings.Join(list, ",")))
+	}
+
+	graph, _ := lib.GetGraph(strings.Join(edges, ", ") + ".")
+	return graph
+}
+
+// naiveDecomposable decides whether the graph has a hypertree decomposition of width K by trying every
+// cover of at most K edges for every component, as in the alternating algorithm k-decomp of Gottlob et
+// al.: the component comp, below a node covered by parent, is decomposable if some cover λ covers the
+// vertices comp shares with parent, contains a vertex of comp, and each [λ]-component within comp is
+// decomposable below λ in turn.
+func naiveDecomposable(graph lib.Graph, K int) bool {
+	edges := graph.Edges.Slice()
+	var covers [][]int // the vertices of each set of at most K edges
+	for set := 1; set < 1<<len(edges); set++ {
+		var cover []int
+		size := 0
+		for i, e := range edges {
+			if set&(1<<i) != 0 {
+				cover = append(cover, e.Vertices...)
+				size++
+			}
+		}
+		if size <= K {
+			covers = append(covers, lib.RemoveDuplicates(cover))
+		}
+	}
+
+	// components returns the components of the vertices outside of sep, connected via the edges
+	components := func(sep []int) [][]int {
+		var comps [][]int
+		var visited []int
+		for _, start := range graph.Vertices() {
+			if contains(sep, start) || contains(visited, start) {
+				continue
+			}
+			comp := []int{start}
+			visited = append(visited, start)
+			for i := 0; i < len(comp); i++ {
+				for _, e := range edges {
+					if !contains(e.Vertices, comp[i]) {
+						continue
+					}
+					for _, v := range e.Vertices {
+						if !contains(sep, v) && !contains(visited, v) {
+							comp = append(comp, v)
+							visited = append(visited, v)
+						}
+					}
+				}
+			}
+			comps = append(comps, comp)
+		}
+		return comps
+	}
+
+	var decomposable func(comp, parent []int) bool
+	decomposable = func(comp, parent []int) bool {
+		var shared []int // the vertices of the edges touching comp, which lie in the parent cover
+		for _, e := range edges {
+			if len(lib.Inter(e.Vertices, comp)) > 0 {
+				shared = append(shared, lib.Inter(e.Vertices, parent)...)
+			}
+		}
+
+	covers:
+		for _, cover := range covers {
+			if !lib.Subset(shared, cover) || len(lib.Inter(cover, comp)) == 0 {
+				continue
+			}
+			for _, child := range components(cover) {
+				if lib.Subset(child, comp) && !decomposable(child, cover) {
+					continue covers
+				}
+			}
+			return true
+		}
+		return false
+	}
+
+	return decomposable(graph.Vertices(), []int{})
+}
+
+func TestRandomGraphsAgainstNaive(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	widths := make(map[int]int) // the number of graphs of each width, s.t. the test covers more than one
+
+	for i := 0; i < 60; i++ {
+		n := 4 + r.Intn(3)
+		graph := randomGraph(r, n, n+r.Intn(n*(n-1)/2-n+1), 2+r.Intn(2))
+
+		for K := 1; K <= 3; K++ {
+			want := naiveDecomposable(graph, K)
+
+			l := &LogKDecomp{Graph: graph, K: K, BalFactor: 2}
+			decomp := l.FindDecomp()
+			if got := !IsEmptyDecomp(decomp); got != want {
+				t.Fatalf("graph %d, %v: found a decomp of width %d: %v, want %v", i, graph, K, got, want)
+			}
+			if want && (!decomp.Correct(graph) || decomp.CheckWidth() > K) {
+				t.Fatalf("graph %d, %v: incorrect decomp of width %d: %v", i, graph, K, decomp)
+			}
+			if want {
+				widths[K]++
+				break
+			}
+		}
+	}
+
+	if widths[1] == 0 || widths[2] == 0 || widths[3] == 0 {
+		t.Errorf("got graphs of the widths %v, want some of each width up to 3", widths)
+	}
+}
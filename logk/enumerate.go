@@ -0,0 +1,77 @@
+package logk
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// decompCollector gathers the distinct decomps found by the root call, which keeps on searching past the
+// first success until enough of them are found. Only the root call uses it, so no locking is needed.
+type decompCollector struct {
+	n       int
+	seen    map[string]bool
+	decomps []lib.Decomp
+}
+
+// rootSignature identifies a decomp by the bag and cover of its root
+func rootSignature(d lib.Decomp) string {
+	bag := append([]int{}, d.Root.Bag...)
+	sort.Ints(bag)
+
+	var cover []int
+	for _, e := range d.Root.Cover.Slice() {
+		cover = append(cover, e.Name)
+	}
+	sort.Ints(cover)
+
+	return fmt.Sprint(bag, cover)
+}
+
+// add stores d, unless a decomp with the same root was found before, and reports whether it is new.
+// Without a collector, every decomp counts as new.
+func (c *decompCollector) add(d lib.Decomp) bool {
+	if c == nil {
+		return true
+	}
+
+	signature := rootSignature(d)
+	if c.seen[signature] {
+		return false
+	}
+	c.seen[signature] = true
+	c.decomps = append(c.decomps, d)
+
+	return true
+}
+
+// more reports whether the root call has to keep on searching for further decomps
+func (c *decompCollector) more() bool {
+	return c != nil && len(c.decomps) < c.n
+}
+
+// found reports whether any decomp was collected so far
+func (c *decompCollector) found() bool {
+	return c != nil && len(c.decomps) > 0
+}
+
+// FindDecompsUpTo finds up to n decomps whose roots differ in their bag or cover. Instead of returning
+// the first decomp found, the root call continues its search over the child and parent separators, so
+// this may take far longer than FindDecomp, all the more if fewer than n such decomps exist. Like
+// FindDecomp, it panics if an invariant of the algorithm is violated.
+func (l *LogKDecomp) FindDecompsUpTo(n int) []lib.Decomp {
+	if n <= 0 {
+		return nil
+	}
+
+	l.collector = &decompCollector{n: n, seen: make(map[string]bool)}
+	defer func() { l.collector = nil }()
+
+	decomp := l.FindDecomp()
+	if !l.collector.found() && !IsEmptyDecomp(decomp) { // the root call was a base case
+		return []lib.Decomp{decomp}
+	}
+
+	return l.collector.decomps
+}
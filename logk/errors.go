@@ -0,0 +1,36 @@
+package logk
+
+import "errors"
+
+// The errors returned by the decomposition algorithms, to be checked via errors.Is
+var (
+	// ErrNoDecomposition signals that the search space was exhausted without finding a decomposition
+	ErrNoDecomposition = errors.New("no decomposition of the given width exists")
+
+	// ErrIncomplete signals that no decomposition was found, but that the search abandoned some recursive
+	// calls due to LogKDecomp.SubTimeout or LogKDecomp.MaxCandidates, or failed to attach some subtrees,
+	// so one may still exist
+	ErrIncomplete = errors.New("no decomposition found, but the search was incomplete")
+
+	// ErrCancelled signals that the search was cancelled before it could finish
+	ErrCancelled = errors.New("search was cancelled")
+
+	// ErrTimeout signals that the search was aborted since it ran out of time
+	ErrTimeout = errors.New("search timed out")
+
+	// ErrDepthExceeded signals that the search exceeded the maximal recursion depth
+	ErrDepthExceeded = errors.New("recursion depth limit exceeded")
+
+	// ErrInvariantViolated signals an internal inconsistency of the algorithm
+	ErrInvariantViolated = errors.New("invariant violated")
+
+	// ErrInvalidWidth signals that the width parameter is not a positive integer
+	ErrInvalidWidth = errors.New("width must be a positive, non-zero integer")
+
+	// ErrRestoreFailed signals that the reductions of the graph could not be undone on a decomposition
+	ErrRestoreFailed = errors.New("restoring the reduced graph failed")
+
+	// ErrNotConnected signals that the nodes of a decomposition containing some vertex don't form a
+	// connected subtree
+	ErrNotConnected = errors.New("connectedness condition violated")
+)
@@ -0,0 +1,93 @@
+package logk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+func TestFindDecompResultErrors(t *testing.T) {
+	triangle, _ := lib.GetGraph("e0(x0,x1), e1(x1,x2), e2(x2,x0).")
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+
+	tests := []struct {
+		name   string
+		solver *LogKDecomp
+		ctx    context.Context
+		want   error
+	}{
+		{"no decomposition", &LogKDecomp{Graph: triangle, K: 1, BalFactor: 2}, nil, ErrNoDecomposition},
+		{"invalid width", &LogKDecomp{Graph: triangle, K: 0, BalFactor: 2}, nil, ErrInvalidWidth},
+		{"cancelled", &LogKDecomp{Graph: triangle, K: 2, BalFactor: 2}, cancelled, ErrCancelled},
+		{"timeout", &LogKDecomp{Graph: triangle, K: 2, BalFactor: 2}, expired, ErrTimeout},
+		{"depth exceeded", &LogKDecomp{Graph: gridGraph(3, 8), K: 2, BalFactor: 2, DepthLimit: 1}, nil,
+			ErrDepthExceeded},
+		{"success", &LogKDecomp{Graph: triangle, K: 2, BalFactor: 2}, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.ctx != nil {
+				tt.solver.SetContext(tt.ctx)
+			}
+
+			decomp, err := tt.solver.FindDecompResult()
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got error %v, want %v", err, tt.want)
+			}
+			if got := !IsEmptyDecomp(decomp); got != (tt.want == nil) {
+				t.Errorf("got a decomp: %v, despite the error %v", got, err)
+			}
+		})
+	}
+}
+
+func TestFindDecompResultEmptyGraph(t *testing.T) {
+	// the empty graph has the trivial decomp of width 0, which the search itself would report as a failure
+	solvers := []ResultAlgorithm{
+		&LogKDecomp{Graph: lib.Graph{}, K: 1, BalFactor: 2},
+		&LogKHybrid{Graph: lib.Graph{}, K: 1, BalFactor: 2},
+	}
+
+	for _, solver := range solvers {
+		t.Run(solver.Name(), func(t *testing.T) {
+			decomp, err := solver.FindDecompResult()
+			if err != nil {
+				t.Fatalf("got error %v, want none", err)
+			}
+			if !IsEmptyGraph(decomp.Graph) || decomp.CheckWidth() != 0 {
+				t.Errorf("got %v, want the trivial decomp of width 0", decomp)
+			}
+
+			decomp, err = solver.FindDecompGraphResult(lib.Graph{})
+			if err != nil || !IsEmptyGraph(decomp.Graph) {
+				t.Errorf("FindDecompGraphResult: got %v and the error %v, want the trivial decomp", decomp, err)
+			}
+		})
+	}
+}
+
+func TestInvariantViolated(t *testing.T) {
+	triangle, _ := lib.GetGraph("e0(x0,x1), e1(x1,x2), e2(x2,x0).")
+	l := &LogKDecomp{Graph: triangle, K: 2, BalFactor: 2}
+	l.negativeCache().Init()
+
+	// a vertex outside of the subgraph to connect to breaks the invariant checked by every recursive call
+	outside := 0
+	for _, v := range triangle.Vertices() {
+		if v >= outside {
+			outside = v + 1
+		}
+	}
+	_, err := l.findDecomp(context.Background(), triangle, []int{outside}, triangle.Edges, 0)
+	if !errors.Is(err, ErrInvariantViolated) {
+		t.Errorf("got error %v, want %v", err, ErrInvariantViolated)
+	}
+}
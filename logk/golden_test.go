@@ -0,0 +1,57 @@
+package logk
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// readGraph parses the graph bundled in testdata under the given name
+func readGraph(tb testing.TB, name string) lib.Graph {
+	tb.Helper()
+	data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	graph, _ := lib.GetGraph(string(data))
+	return graph
+}
+
+func TestKnownWidths(t *testing.T) {
+	tests := []struct {
+		graph string
+		width int // the hypertree width of the graph
+	}{
+		{"acyclic.hg", 1},
+		{"triangle.hg", 2},
+		{"k5.hg", 3},
+		{"grid3x4.hg", 2},
+		{"grid4x4.hg", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.graph, func(t *testing.T) {
+			graph := readGraph(t, tt.graph)
+
+			l := &LogKDecomp{Graph: graph, K: tt.width, BalFactor: 2}
+			decomp := l.FindDecomp()
+			if IsEmptyDecomp(decomp) {
+				t.Fatalf("found no decomp of width %d", tt.width)
+			}
+			if !decomp.Correct(graph) {
+				t.Errorf("incorrect decomp %v", decomp)
+			}
+			if got := decomp.CheckWidth(); got != tt.width {
+				t.Errorf("got a decomp of width %d, want %d", got, tt.width)
+			}
+
+			// no graph has a decomp below its hypertree width
+			l = &LogKDecomp{Graph: graph, K: tt.width - 1, BalFactor: 2}
+			if tt.width > 1 && !IsEmptyDecomp(l.FindDecomp()) {
+				t.Errorf("found a decomp of width %d", tt.width-1)
+			}
+		})
+	}
+}
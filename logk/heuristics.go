@@ -0,0 +1,49 @@
+package logk
+
+import (
+	"fmt"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// ordering is an ordering of the edges, used to find separators faster
+type ordering struct {
+	name  string
+	order func(edges lib.Edges) lib.Edges
+}
+
+// orderings lists the heuristics of lib, by the number used to select them on the command line
+var orderings = map[int]ordering{
+	1: {name: "degree ordering", order: lib.GetDegreeOrder},
+	2: {name: "max separator ordering", order: lib.GetMaxSepOrder},
+	3: {name: "MSC ordering", order: lib.GetMSCOrder},
+	4: {name: "edge degree ordering", order: lib.GetEdgeDegreeOrder},
+}
+
+// HeuristicName returns the human-readable name of heuristic h, and false if there is no such heuristic
+func HeuristicName(h int) (string, bool) {
+	o, ok := orderings[h]
+	return o.name, ok
+}
+
+// HeuristicOrder returns the ordering of the edges used by heuristic h, and false if there is no such
+// heuristic
+func HeuristicOrder(h int) (func(edges lib.Edges) lib.Edges, bool) {
+	o, ok := orderings[h]
+	return o.order, ok
+}
+
+// ApplyHeuristic orders the edges of g using heuristic h, numbered just like the -heuristic flag, and
+// returns the reordered graph alongside the name of the heuristic. Heuristic 0 leaves g unchanged.
+func ApplyHeuristic(g lib.Graph, h int) (lib.Graph, string, error) {
+	if h == 0 {
+		return g, "no ordering", nil
+	}
+	o, ok := orderings[h]
+	if !ok {
+		return g, "", fmt.Errorf("unknown heuristic %d", h)
+	}
+
+	g.Edges = o.order(g.Edges)
+	return g, o.name, nil
+}
@@ -1,34 +1,40 @@
-package main
+package logk
 
 // Hybrid algorithm of log-k-decomp and det-k-decomp.
 
 import (
+	"bytes"
 	"fmt"
 	"log"
-	"reflect"
+	"math"
 	"runtime"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
 )
 
-// HybridPredicate is used to determine when to switch from LogKDecomp to using DetKDecomp
-type HybridPredicate = func(H lib.Graph, K int) bool
+// HybridPredicate is used to determine when to switch from LogKDecomp to using DetKDecomp, given the
+// current subgraph, the width and the current recursion depth
+type HybridPredicate = func(H lib.Graph, K int, depth int) bool
 
-type recursiveCall = func(H lib.Graph, Conn []int, allwowed lib.Edges, recDepth int) lib.Decomp
+type recursiveCall = func(H lib.Graph, Conn []int, allwowed lib.Edges, recDepth int) (lib.Decomp, error)
 
 // LogKHybrid implements a hybridised algorithm, using LogKDecomp and DetKDecomp in tandem
 type LogKHybrid struct {
-	Graph     lib.Graph
-	K         int
-	cache     lib.Cache
-	BalFactor int
-	Predicate HybridPredicate // used to determine when to switch to DetK
-	Size      int
-	level     int // keep track of
+	Graph        lib.Graph
+	K            int
+	cache        lib.Cache
+	BalFactor    int
+	Predicate    HybridPredicate // used to determine when to switch to DetK
+	Size         int
+	CollectStats bool      // if set, counts the decisions of the predicate, as reported by Stats
+	level        int       // keep track of
+	rootChild    lib.Edges // the balanced separator chosen by the root call of the last search
+	rootParent   lib.Edges // the parent separator chosen above it by the root call, if any
+	stats        hybridStats
 }
 
 // OneRoundPred will match the behaviour of BalDetK, with Depth 1
-func (l *LogKHybrid) OneRoundPred(H lib.Graph, K int) bool {
+func (l *LogKHybrid) OneRoundPred(H lib.Graph, K int, depth int) bool {
 
 	// log.Println("One Round Predicate")
 
@@ -36,7 +42,7 @@ func (l *LogKHybrid) OneRoundPred(H lib.Graph, K int) bool {
 }
 
 // NumberEdgesPred checks the number of edges of the subgraph
-func (l *LogKHybrid) NumberEdgesPred(H lib.Graph, K int) bool {
+func (l *LogKHybrid) NumberEdgesPred(H lib.Graph, K int, depth int) bool {
 
 	output := H.Edges.Len() < l.Size
 
@@ -49,7 +55,7 @@ func (l *LogKHybrid) NumberEdgesPred(H lib.Graph, K int) bool {
 }
 
 // SumEdgesPred checks the sum over all edges of the subgraph
-func (l *LogKHybrid) SumEdgesPred(H lib.Graph, K int) bool {
+func (l *LogKHybrid) SumEdgesPred(H lib.Graph, K int, depth int) bool {
 	count := 0
 
 	for i := range H.Edges.Slice() {
@@ -68,7 +74,7 @@ func (l *LogKHybrid) SumEdgesPred(H lib.Graph, K int) bool {
 }
 
 // ETimesKDivAvgEdgePred checks a complex formula over the subgraph and used K
-func (l *LogKHybrid) ETimesKDivAvgEdgePred(H lib.Graph, K int) bool {
+func (l *LogKHybrid) ETimesKDivAvgEdgePred(H lib.Graph, K int, depth int) bool {
 
 	count := 0
 
@@ -89,36 +95,133 @@ func (l *LogKHybrid) ETimesKDivAvgEdgePred(H lib.Graph, K int) bool {
 
 }
 
+// DepthPred switches to DetK once the recursion depth exceeds the logarithm of the number of edges of
+// the input graph, i.e. the depth LogKDecomp is guaranteed to stay within
+func (l *LogKHybrid) DepthPred(H lib.Graph, K int, depth int) bool {
+	return float64(depth) > math.Log2(float64(l.Graph.Edges.Len()))
+}
+
+// LogEdgesPred switches to DetK once the estimated recursion depth of the subgraph, i.e. the logarithm of
+// its number of edges, is at most Size, as the parallel overhead isn't worth it for small subproblems
+func (l *LogKHybrid) LogEdgesPred(H lib.Graph, K int, depth int) bool {
+	return math.Log2(float64(H.Edges.Len())) <= float64(l.Size)
+}
+
 // SetWidth sets the current width parameter of the algorithm
 func (l *LogKHybrid) SetWidth(K int) {
-	l.cache.Reset() // reset the cache as the new width might invalidate any old results
+	if K > l.K { // negative results at width K still hold for any smaller width
+		l.cache.Reset()
+	}
 
 	l.K = K
 }
 
+// ResetCaches drops all cached results, s.t. the next search starts from scratch
+func (l *LogKHybrid) ResetCaches() {
+	l.cache.Reset()
+}
+
 // Name returns the name of the algorithm
 func (l *LogKHybrid) Name() string {
 	return "LogKHybrid"
 }
 
-// FindDecomp finds a decomp
+// FindDecomp finds a decomp. It panics if an invariant of the algorithm is violated,
+// use FindDecompResult to have this reported as an error instead.
 func (l *LogKHybrid) FindDecomp() lib.Decomp {
+	decomp, err := l.search()
+	if err != nil {
+		log.Panicln(err)
+	}
+
+	return decomp
+}
+
+// search runs the actual search on the graph of the algorithm
+func (l *LogKHybrid) search() (lib.Decomp, error) {
 	l.cache.Init()
+	l.rootChild, l.rootParent = lib.Edges{}, lib.Edges{}
 
 	return l.findDecomp(l.Graph, []int{}, l.Graph.Edges, 0)
 }
 
+// FindDecompResult finds a decomp, and returns an error explaining why none was found otherwise
+func (l *LogKHybrid) FindDecompResult() (lib.Decomp, error) {
+	if l.K <= 0 {
+		return lib.Decomp{}, ErrInvalidWidth
+	}
+	if IsEmptyGraph(l.Graph) { // the trivial decomp, which the search would report as a failure
+		return lib.Decomp{Graph: l.Graph}, nil
+	}
+
+	decomp, err := l.search()
+	if err != nil {
+		return lib.Decomp{}, err
+	}
+
+	if IsEmptyDecomp(decomp) {
+		return decomp, ErrNoDecomposition
+	}
+
+	return decomp, nil
+}
+
+// RootSeparator returns the balanced separator chosen by the root call of the last search, and the parent
+// separator chosen above it, which is empty if the child could serve as the root. ok is false if the root
+// call found no decomp using a separator.
+func (l *LogKHybrid) RootSeparator() (child lib.Edges, parent lib.Edges, ok bool) {
+	return l.rootChild, l.rootParent, l.rootChild.Len() > 0
+}
+
+// Stats returns the decisions of the predicate counted since the last call of ResetStats, if CollectStats is set
+func (l *LogKHybrid) Stats() HybridStats {
+	return l.stats.snapshot()
+}
+
+// ResetStats sets the counted decisions of the predicate back to zero
+func (l *LogKHybrid) ResetStats() {
+	l.stats.reset()
+}
+
 // FindDecompGraph finds a decomp, for an explicit graph
 func (l *LogKHybrid) FindDecompGraph(Graph lib.Graph) lib.Decomp {
 	l.Graph = Graph
 	return l.FindDecomp()
 }
 
-func (l *LogKHybrid) detKWrapper(H lib.Graph, Conn []int, allwowed lib.Edges, recDepth int) lib.Decomp {
+// FindDecompGraphResult finds a decomp for an explicit graph just like FindDecompResult, reporting errors
+// instead of causing a panic. The graph of the algorithm is left unchanged.
+func (l *LogKHybrid) FindDecompGraphResult(Graph lib.Graph) (lib.Decomp, error) {
+	oldGraph := l.Graph
+	defer func() { l.Graph = oldGraph }()
+
+	l.Graph = Graph
+	return l.FindDecompResult()
+}
+
+// FindDecompGraphK finds a decomp of width k for an explicit graph. The graph and width of the algorithm
+// are left unchanged, even if the search panics. Changing the width affects the caches as in SetWidth.
+func (l *LogKHybrid) FindDecompGraphK(Graph lib.Graph, k int) lib.Decomp {
+	oldGraph, oldK := l.Graph, l.K
+	defer func() {
+		l.Graph = oldGraph
+		if l.K != oldK {
+			l.SetWidth(oldK)
+		}
+	}()
+
+	l.Graph = Graph
+	if l.K != k {
+		l.SetWidth(k)
+	}
+	return l.FindDecomp()
+}
+
+func (l *LogKHybrid) detKWrapper(H lib.Graph, Conn []int, allwowed lib.Edges, recDepth int) (lib.Decomp, error) {
 	det := DetKDecomp{K: l.K, Graph: lib.Graph{Edges: allwowed}, BalFactor: l.BalFactor, SubEdge: false}
 
 	l.cache.CopyRef(&det.cache) // reuse the same cache as log-k
-	return det.findDecomp(H, Conn, recDepth)
+	return det.findDecomp(H, Conn, recDepth), nil
 }
 
 // determine whether we have reached a (positive or negative) base case
@@ -166,7 +269,7 @@ func (l *LogKHybrid) baseCase(H lib.Graph, lenAE int) lib.Decomp {
 	return output
 }
 
-func (l *LogKHybrid) findDecomp(H lib.Graph, Conn []int, allowedFull lib.Edges, recDepth int) lib.Decomp {
+func (l *LogKHybrid) findDecomp(H lib.Graph, Conn []int, allowedFull lib.Edges, recDepth int) (lib.Decomp, error) {
 	recDepth = recDepth + 1 // increase the recursive depth
 
 	// log.Printf("\n\nCurrent SubGraph: %v\n", H)
@@ -174,22 +277,30 @@ func (l *LogKHybrid) findDecomp(H lib.Graph, Conn []int, allowedFull lib.Edges,
 	// log.Println("Conn: ", PrintVertices(Conn), "\n\n")
 
 	if !lib.Subset(Conn, H.Vertices()) {
-		log.Panicln("Conn invariant violated.")
+		var dump bytes.Buffer
+		fmt.Fprintln(&dump, "Current SubGraph, ", H)
+		fmt.Fprintln(&dump, "Conn ", lib.PrintVertices(Conn))
+
+		return lib.Decomp{}, invariantError("Conn invariant violated.", &dump)
 	}
 
 	// Base Case
 	if l.baseCaseCheck(H.Edges.Len(), len(H.Special), allowedFull.Len()) {
-		return l.baseCase(H, allowedFull.Len())
+		return l.baseCase(H, allowedFull.Len()), nil
 	}
 
 	// Determine the function to use for the recursive calls
 	var recCall recursiveCall
 
-	if l.Predicate(H, l.K) {
+	fired := l.Predicate(H, l.K, recDepth)
+	if fired {
 		recCall = l.detKWrapper
 	} else {
 		recCall = l.findDecomp
 	}
+	if l.CollectStats {
+		l.stats.add(fired, H.Edges.Len())
+	}
 
 	//all vertices within (H ∪ Sp)
 	verticesH := append(H.Vertices())
@@ -231,8 +342,11 @@ CHILD:
 				VCompε := compsε[y].Vertices()
 				Connγ := lib.Inter(VCompε, childχ)
 
-				decomp := recCall(compsε[y], Connγ, allowedFull, recDepth)
-				if reflect.DeepEqual(decomp, lib.Decomp{}) {
+				decomp, err := recCall(compsε[y], Connγ, allowedFull, recDepth)
+				if err != nil {
+					return lib.Decomp{}, err
+				}
+				if IsEmptyDecomp(decomp) {
 					// log.Println("Rejecting child-root")
 					// log.Printf("\nCurrent SubGraph: %v\n", H)
 					// log.Printf("Current Allowed Edges: %v\n", allowed)
@@ -246,7 +360,10 @@ CHILD:
 			}
 
 			root := lib.Node{Bag: childχ, Cover: childλ, Children: subtrees}
-			return lib.Decomp{Graph: H, Root: root}
+			if recDepth == 1 {
+				l.rootChild = childλ
+			}
+			return lib.Decomp{Graph: H, Root: root}, nil
 		}
 
 		allowedParent := lib.FilterVertices(allowed, append(Conn, childλ.Vertices()...))
@@ -276,20 +393,21 @@ CHILD:
 				}
 			}
 			if !foundLow {
-				fmt.Println("Current SubGraph, ", H)
-				fmt.Println("Conn ", lib.PrintVertices(Conn))
-				fmt.Printf("Current Allowed Edges: %v\n", allowed)
-				fmt.Printf("Current Allowed Edges in Parent Search: %v\n", parentalSearch.Edges)
-				fmt.Println("Child ", childλ, "  ", lib.PrintVertices(childλ.Vertices()))
-				fmt.Println("Comps of child ", compsε)
-				fmt.Println("parent ", parentλ, "( ", parentalSearch.Result, " ) from the set: ", allowedParent)
-				fmt.Println("Comps of p: ")
+				var dump bytes.Buffer
+				fmt.Fprintln(&dump, "Current SubGraph, ", H)
+				fmt.Fprintln(&dump, "Conn ", lib.PrintVertices(Conn))
+				fmt.Fprintf(&dump, "Current Allowed Edges: %v\n", allowed)
+				fmt.Fprintf(&dump, "Current Allowed Edges in Parent Search: %v\n", parentalSearch.Edges)
+				fmt.Fprintln(&dump, "Child ", childλ, "  ", lib.PrintVertices(childλ.Vertices()))
+				fmt.Fprintln(&dump, "Comps of child ", compsε)
+				fmt.Fprintln(&dump, "parent ", parentλ, "( ", parentalSearch.Result, " ) from the set: ", allowedParent)
+				fmt.Fprintln(&dump, "Comps of p: ")
 				for i := range compsπ {
-					fmt.Println("Component: ", compsπ[i], " Len: ", compsπ[i].Len())
+					fmt.Fprintln(&dump, "Component: ", compsπ[i], " Len: ", compsπ[i].Len())
 
 				}
 
-				log.Panicln("the parallel search didn't actually find a valid parent")
+				return lib.Decomp{}, invariantError("the parallel search didn't actually find a valid parent", &dump)
 			}
 
 			vertCompLow := compLow.Vertices()
@@ -319,7 +437,7 @@ CHILD:
 
 			//Computing upper component in parallel
 
-			chanUp := make(chan lib.Decomp)
+			chanUp := make(chan decompInt, 1) // buffered, so abandoned senders never block
 
 			var compUp lib.Graph
 			var decompUp lib.Decomp
@@ -338,6 +456,9 @@ CHILD:
 			// specialChild = NewEdges([]Edge{Edge{Vertices: Inter(childχ, comp_up.Vertices())}})
 			specialChild = lib.NewEdges([]lib.Edge{{Vertices: childχ}})
 
+			// keep track of the number of goroutines that send their result back
+			numSenders := 0
+
 			// if no comps_p, other than comp_low, just use parent as is
 			if len(compsπ) == 1 {
 				compUp.Edges = parentλ
@@ -348,8 +469,9 @@ CHILD:
 				decompTemp := lib.Decomp{Graph: compUp, Root: lib.Node{Bag: lib.Inter(parentλ.Vertices(), verticesH),
 					Cover: parentλ, Children: []lib.Node{{Bag: childχ, Cover: childλ}}}}
 
+				numSenders++
 				go func(decomp lib.Decomp) {
-					chanUp <- decomp
+					chanUp <- decompInt{Decomp: decomp}
 				}(decompTemp)
 
 			} else if len(tempEdgeSlice) > 0 { // otherwise compute decomp for comp_up
@@ -364,21 +486,41 @@ CHILD:
 				//Reducing the allowed edges
 				allowedReduced := allowedFull.Diff(compLow.Edges)
 
+				numSenders++
 				go func(comp_up lib.Graph, Conn []int, allowedReduced lib.Edges) {
-					chanUp <- recCall(comp_up, Conn, allowedReduced, recDepth)
+					var out decompInt
+					out.Decomp, out.Err = recCall(comp_up, Conn, allowedReduced, recDepth)
+					chanUp <- out
 				}(compUp, Conn, allowedReduced)
+			} else { // the other comps_p only have special edges, so use parent as is, with them as leaves
+				compUp.Edges = parentλ
+				compUp.Special = append(tempSpecialSlice, specialChild)
+
+				children := []lib.Node{{Bag: childχ, Cover: childλ}}
+				for _, sp := range tempSpecialSlice {
+					children = append(children, lib.Node{Bag: sp.Vertices(), Cover: sp})
+				}
+				decompTemp := lib.Decomp{Graph: compUp, Root: lib.Node{Bag: lib.Inter(parentλ.Vertices(), verticesH),
+					Cover: parentλ, Children: children}}
+
+				numSenders++
+				go func(decomp lib.Decomp) {
+					chanUp <- decompInt{Decomp: decomp}
+				}(decompTemp)
 			}
 
 			// Parallel Recursive Calls:
-			ch := make(chan decompInt)
+			ch := make(chan decompInt, len(compsε)) // buffered, so abandoned senders never block
 			var subtrees []lib.Node
 
+			numSenders = numSenders + len(compsε)
+
 			for x := range compsε {
 				Connχ := lib.Inter(compsε[x].Vertices(), childχ)
 
 				go func(x int, comps_c []lib.Graph, Conn_x []int, allowedFull lib.Edges) {
 					var out decompInt
-					out.Decomp = recCall(comps_c[x], Conn_x, allowedFull, recDepth)
+					out.Decomp, out.Err = recCall(comps_c[x], Conn_x, allowedFull, recDepth)
 					out.Int = x
 					ch <- out
 				}(x, compsε, Connχ, allowedFull)
@@ -388,11 +530,14 @@ CHILD:
 			// 2. WAIT ON GOROUTINES TO FINISH
 			// ---------------------
 
-			for i := 0; i < len(compsε)+1; i++ {
+			for i := 0; i < numSenders; i++ {
 				select {
 				case decompInt := <-ch:
+					if decompInt.Err != nil {
+						return lib.Decomp{}, decompInt.Err
+					}
 
-					if reflect.DeepEqual(decompInt.Decomp, lib.Decomp{}) {
+					if IsEmptyDecomp(decompInt.Decomp) {
 
 						// l.cache.AddNegative(childλ, comps_c[x])
 						// log.Println("Rejecting child")
@@ -402,9 +547,13 @@ CHILD:
 					// log.Printf("Produced Decomp: %+v\n", decomp)
 					subtrees = append(subtrees, decompInt.Decomp.Root)
 
-				case decompUpChan := <-chanUp:
+				case decompUpInt := <-chanUp:
+					if decompUpInt.Err != nil {
+						return lib.Decomp{}, decompUpInt.Err
+					}
+					decompUpChan := decompUpInt.Decomp
 
-					if reflect.DeepEqual(decompUpChan, lib.Decomp{}) {
+					if IsEmptyDecomp(decompUpChan) {
 
 						// l.addNegative(childχ, comp_up, Sp)
 						// log.Println("Rejecting comp_up ", comp_up, " of H ", H)
@@ -413,20 +562,21 @@ CHILD:
 					}
 
 					if !lib.Subset(Conn, decompUpChan.Root.Bag) {
-						fmt.Println("Current SubGraph, ", H)
-						fmt.Println("Conn ", lib.PrintVertices(Conn))
-						fmt.Printf("Current Allowed Edges: %v\n", allowed)
-						fmt.Printf("Current Allowed Edges in Parent Search: %v\n", parentalSearch.Edges)
-						fmt.Println("Child ", childλ, "  ", lib.PrintVertices(childλ.Vertices()))
-						fmt.Println("Comps of child ", compsε)
-						fmt.Println("parent ", parentλ, "( ", parentalSearch.Result, " ) from the set: ", allowedParent)
-						fmt.Println("comp_up ", compUp, " V(comp_up) ", lib.PrintVertices(compUp.Vertices()))
-						fmt.Println("Decomp up:  ", decompUpChan)
-						fmt.Println("Comps of p", compsπ)
+						var dump bytes.Buffer
+						fmt.Fprintln(&dump, "Current SubGraph, ", H)
+						fmt.Fprintln(&dump, "Conn ", lib.PrintVertices(Conn))
+						fmt.Fprintf(&dump, "Current Allowed Edges: %v\n", allowed)
+						fmt.Fprintf(&dump, "Current Allowed Edges in Parent Search: %v\n", parentalSearch.Edges)
+						fmt.Fprintln(&dump, "Child ", childλ, "  ", lib.PrintVertices(childλ.Vertices()))
+						fmt.Fprintln(&dump, "Comps of child ", compsε)
+						fmt.Fprintln(&dump, "parent ", parentλ, "( ", parentalSearch.Result, " ) from the set: ", allowedParent)
+						fmt.Fprintln(&dump, "comp_up ", compUp, " V(comp_up) ", lib.PrintVertices(compUp.Vertices()))
+						fmt.Fprintln(&dump, "Decomp up:  ", decompUpChan)
+						fmt.Fprintln(&dump, "Comps of p", compsπ)
 
 						//fmt.Println("Compare against PredSearch: ", predPar.Check(&H, &parentλ, l.BalFactor, Vertices))
 
-						log.Panicln("Conn not covered in parent, Wait, what?")
+						return lib.Decomp{}, invariantError("Conn not covered in parent, Wait, what?", &dump)
 					}
 
 					decompUp = decompUpChan
@@ -440,14 +590,22 @@ CHILD:
 			rootChild := lib.Node{Bag: childχ, Cover: childλ, Children: subtrees}
 
 			var finalRoot lib.Node
-			if len(tempEdgeSlice) > 0 {
-				finalRoot = attachingSubtrees(decompUp.Root, rootChild, specialChild)
+			if len(tempEdgeSlice) > 0 || len(compsπ) > 1 {
+				var err error
+				finalRoot, err = attachingSubtrees(decompUp.Root, rootChild, specialChild)
+				if err != nil { // reject just this parent
+					logf(LogError, "Warning: rejecting parent %v of child %v: %v\n", parentλ, childλ, err)
+					continue PARENT
+				}
 			} else {
 				finalRoot = rootChild
 			}
 
 			// log.Printf("Produced Decomp: %v\n", finalRoot)
-			return lib.Decomp{Graph: H, Root: finalRoot}
+			if recDepth == 1 {
+				l.rootChild, l.rootParent = childλ, parentλ
+			}
+			return lib.Decomp{Graph: H, Root: finalRoot}, nil
 		}
 
 		// if parentFound {
@@ -459,5 +617,5 @@ CHILD:
 	}
 
 	// exhausted search space
-	return lib.Decomp{}
+	return lib.Decomp{}, nil
 }
@@ -0,0 +1,100 @@
+package logk
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// gridGraph returns the n×m grid, with one binary edge between neighbouring vertices. Its hypertree width
+// grows with min(n, m), and searches for widths slightly below it take a long time to fail.
+func gridGraph(n, m int) lib.Graph {
+	var edges []string
+	for i := 0; i < n; i++ {
+		for j := 0; j < m; j++ {
+			if j+1 < m {
+				edges = append(edges, fmt.Sprintf("e%d(v%d_%d,v%d_%d)", len(edges), i, j, i, j+1))
+			}
+			if i+1 < n {
+				edges = append(edges, fmt.Sprintf("e%d(v%d_%d,v%d_%d)", len(edges), i, j, i+1, j))
+			}
+		}
+	}
+	graph, _ := lib.GetGraph(strings.Join(edges, ",\n") + ".")
+	return graph
+}
+
+// logkGoroutines returns the stacks of the goroutines, other than the caller, running code of this package.
+// Those of lib's separator search are left out: in BalancedGo v1.6.4, each FindNext that finds a separator
+// leaves one behind, blocked on a channel nobody reads, which needs to be fixed in BalancedGo itself.
+func logkGoroutines() []string {
+	buf := make([]byte, 1<<16)
+	for n := runtime.Stack(buf, true); n == len(buf); n = runtime.Stack(buf, true) {
+		buf = make([]byte, 2*len(buf))
+	}
+	buf = bytes.TrimRight(buf, "\x00")
+
+	var stacks []string
+	for _, stack := range strings.Split(string(buf), "\n\n")[1:] { // the first one is the caller's
+		for _, line := range strings.Split(stack, "\n") {
+			if strings.HasPrefix(line, "created by") {
+				break
+			}
+			if strings.HasPrefix(line, "github.com/cem-okulmus/log-k-decomp/logk.") {
+				stacks = append(stacks, stack)
+				break
+			}
+		}
+	}
+	return stacks
+}
+
+// waitGoroutines fails the test unless no goroutine runs code of this package within a second, giving
+// those just returning some time to finish
+func waitGoroutines(t *testing.T) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for stacks := logkGoroutines(); len(stacks) > 0; stacks = logkGoroutines() {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines left after the search:\n%s", len(stacks), strings.Join(stacks, "\n\n"))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCancelledSearchLeavesNoGoroutines(t *testing.T) {
+	l := &LogKDecomp{Graph: gridGraph(6, 6), K: 3, BalFactor: 2}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	l.SetContext(ctx)
+
+	if _, err := l.FindDecompResult(); err != ErrTimeout {
+		t.Fatalf("got error %v, want %v", err, ErrTimeout)
+	}
+
+	waitGoroutines(t)
+}
+
+func TestFailingSearchLeavesNoGoroutines(t *testing.T) {
+	// at width 2, the search abandons PARENT iterations with recursive calls still running. Which ones are
+	// still running depends on the scheduler, so the search is repeated a few times.
+	graph, _ := lib.GetGraph("e0(v0,v3,v4), e1(v4,v8), e2(v8,v5), e3(v0,v7,v4), e4(v3,v1), e5(v8,v0,v4), " +
+		"e6(v3,v8,v7), e7(v8,v2,v1), e8(v8,v3), e9(v4,v0,v6), e10(v1,v5,v7), e11(v0,v1), e12(v2,v5), " +
+		"e13(v0,v7), e14(v1,v6,v4), e15(v5,v4).")
+
+	for i := 0; i < 5; i++ {
+		for K := 1; K <= 2; K++ {
+			l := &LogKDecomp{Graph: graph, K: K, BalFactor: 2}
+			if _, err := l.FindDecompResult(); err != ErrNoDecomposition {
+				t.Fatalf("got error %v at width %d, want %v", err, K, ErrNoDecomposition)
+			}
+			waitGoroutines(t)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package logk
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+)
+
+// LogLevel determines how much the algorithms report via the standard logger
+type LogLevel int32
+
+// The supported log levels, each including all the ones before it
+const (
+	LogError LogLevel = iota // only report errors
+	LogInfo                  // also report the start and the outcome of each search
+	LogDebug                 // also report the separators chosen during the search
+	LogTrace                 // also report each subproblem and every rejected separator
+)
+
+var logLevelNames = []string{"error", "info", "debug", "trace"}
+
+func (level LogLevel) String() string {
+	if level < LogError || int(level) >= len(logLevelNames) {
+		return fmt.Sprintf("LogLevel(%d)", level)
+	}
+	return logLevelNames[level]
+}
+
+// ParseLogLevel returns the log level of the given name
+func ParseLogLevel(name string) (LogLevel, error) {
+	for i, n := range logLevelNames {
+		if strings.EqualFold(name, n) {
+			return LogLevel(i), nil
+		}
+	}
+	return LogError, fmt.Errorf("unknown log level %q, supported are: %s", name, strings.Join(logLevelNames, ", "))
+}
+
+var logLevel = int32(LogError)
+
+// SetLogLevel sets the level of the logs of all algorithms, by default only errors are logged
+func SetLogLevel(level LogLevel) {
+	atomic.StoreInt32(&logLevel, int32(level))
+}
+
+// logEnabled checks if messages of the given level get logged, to skip the work of preparing them otherwise
+func logEnabled(level LogLevel) bool {
+	return LogLevel(atomic.LoadInt32(&logLevel)) >= level
+}
+
+// logf logs the message if its level is enabled
+func logf(level LogLevel, format string, v ...interface{}) {
+	if logEnabled(level) {
+		log.Printf(format, v...)
+	}
+}
@@ -0,0 +1,55 @@
+// Package logk implements log-k-decomp, a parallel algorithm to compute Hypertree Decompositions
+// with logarithmic recursion depth, as well as a hybrid of it with det-k-decomp.
+//
+// A decomposition can be computed without any of the command line tool, e.g.:
+//
+//	solver := logk.LogKDecomp{Graph: graph, K: 3, BalFactor: 2}
+//	decomp := solver.FindDecomp()
+package logk
+
+import (
+	"context"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// Algorithm serves as the common interface of all hypergraph decomposition algorithms
+type Algorithm interface {
+	// A Name is useful to identify the individual algorithms in the result
+	Name() string
+	FindDecomp() lib.Decomp
+	FindDecompGraph(G lib.Graph) lib.Decomp
+	SetWidth(K int)
+}
+
+// ResultAlgorithm is an Algorithm that can also report why no decomposition was found
+type ResultAlgorithm interface {
+	Algorithm
+	FindDecompResult() (lib.Decomp, error)
+	FindDecompGraphResult(G lib.Graph) (lib.Decomp, error)
+}
+
+// ContextAlgorithm is an Algorithm whose search can be stopped via a context
+type ContextAlgorithm interface {
+	Algorithm
+	FindDecompContext(ctx context.Context) (lib.Decomp, error)
+}
+
+// RootReporter is an Algorithm that can report the separators chosen by the root call of its last search
+type RootReporter interface {
+	Algorithm
+	RootSeparator() (child lib.Edges, parent lib.Edges, ok bool)
+}
+
+// IsEmptyDecomp checks if d is the empty decomp, used to signal that no decomp could be found.
+// This is far cheaper than comparing against lib.Decomp{} via reflection.
+func IsEmptyDecomp(d lib.Decomp) bool {
+	return d.Graph.Edges.Len() == 0 && len(d.Graph.Special) == 0 && len(d.Root.Bag) == 0 &&
+		d.Root.Cover.Len() == 0 && len(d.Root.Children) == 0
+}
+
+// IsEmptyGraph checks if a graph has neither edges nor special edges. Its decomp is the trivial one of
+// width 0, which IsEmptyDecomp can't tell apart from a failed search.
+func IsEmptyGraph(g lib.Graph) bool {
+	return g.Edges.Len() == 0 && len(g.Special) == 0
+}
@@ -0,0 +1,241 @@
+package logk
+
+// replay.go records the separators tried by the recursive calls of LogKDecomp, and replays the ones that
+// led to a decomp, to reconstruct the decomp of a recorded search
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// Decision is a separator tried by a recursive call of LogKDecomp, and whether the call got a decomp with it
+type Decision struct {
+	Width    int      `json:"width"`
+	Subgraph uint64   `json:"subgraph"` // fingerprint of the subgraph and the edges allowed to cover it
+	Depth    int      `json:"depth"`
+	Child    []string `json:"child"`            // the names of the edges of the balanced separator
+	Parent   []string `json:"parent,omitempty"` // the names of the edges of the parent, empty if the child is the root
+	Accepted bool     `json:"accepted"`
+}
+
+// DecisionLog collects the decisions of the searches of LogKDecomp, safe for concurrent use
+type DecisionLog struct {
+	mux       sync.Mutex
+	decisions []Decision
+}
+
+// replayKey identifies the subproblems a decision can be replayed for
+type replayKey struct {
+	width    int
+	subgraph uint64
+}
+
+// Decisions returns the decisions recorded so far, in the order they were made
+func (d *DecisionLog) Decisions() []Decision {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	return append([]Decision{}, d.decisions...)
+}
+
+func (d *DecisionLog) add(decision Decision) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	d.decisions = append(d.decisions, decision)
+}
+
+// WriteJSON writes the decisions as JSON, one per line
+func (d *DecisionLog) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	for _, decision := range d.Decisions() {
+		if err := encoder.Encode(decision); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadDecisionLog reads the decisions written by WriteJSON
+func ReadDecisionLog(r io.Reader) (*DecisionLog, error) {
+	output := &DecisionLog{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24) // separators of large widths make for long lines
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var decision Decision
+		if err := json.Unmarshal(scanner.Bytes(), &decision); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		output.decisions = append(output.decisions, decision)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// accepted indexes the accepted decisions by the subproblems they were made for, in the order they were made
+func (d *DecisionLog) accepted() map[replayKey][]Decision {
+	output := make(map[replayKey][]Decision)
+	for _, decision := range d.Decisions() {
+		if decision.Accepted {
+			key := replayKey{width: decision.Width, subgraph: decision.Subgraph}
+			output[key] = append(output[key], decision)
+		}
+	}
+	return output
+}
+
+// Ambiguous counts the subproblems for which more than one decision got accepted, e.g. by concurrent
+// branches of the search. Replaying such a log tries their decisions in order, and may thus end up with
+// another decomp than the recorded search, which never happens for logs of deterministic searches.
+func (d *DecisionLog) Ambiguous() int {
+	output := 0
+	for _, decisions := range d.accepted() {
+		if len(decisions) > 1 {
+			output++
+		}
+	}
+	return output
+}
+
+// subgraphFingerprint identifies a subproblem by its subgraph and the edges allowed to cover it, just like
+// the positive cache does
+func subgraphFingerprint(H lib.Graph, allowed lib.Edges) uint64 {
+	return H.Hash()*31 + allowed.Hash()
+}
+
+// EdgeNamer names the edges of a graph, e.g. by their names in the input
+type EdgeNamer func(e lib.Edge) string
+
+// edgeName returns the name of an edge in the decision logs
+func (l *LogKDecomp) edgeName(e lib.Edge) string {
+	if l.EdgeName != nil {
+		return l.EdgeName(e)
+	}
+	return e.String()
+}
+
+// edgeNames returns the names of the edges of a separator
+func edgeNames(sep lib.Edges, name EdgeNamer) []string {
+	output := make([]string, 0, sep.Len())
+	for _, e := range sep.Slice() {
+		output = append(output, name(e))
+	}
+	return output
+}
+
+// nameSet identifies a set of edge names regardless of their order
+func nameSet(names []string) string {
+	sorted := append([]string{}, names...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, "\x00")
+}
+
+// edgesByName selects the edges of the given names among the allowed ones, in the order of allowed
+func edgesByName(allowed lib.Edges, names []string, name EdgeNamer) (lib.Edges, bool) {
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	var output []lib.Edge
+	for _, e := range allowed.Slice() {
+		if wanted[name(e)] {
+			output = append(output, e)
+		}
+	}
+	if len(output) != len(names) {
+		return lib.Edges{}, false
+	}
+
+	return lib.NewEdges(output), true
+}
+
+// record adds a decision to the log of the algorithm, if it keeps one
+func (l *LogKDecomp) record(H lib.Graph, allowed lib.Edges, recDepth int, child, parent lib.Edges, accepted bool) {
+	if l.Decisions == nil {
+		return
+	}
+
+	decision := Decision{
+		Width:    l.K,
+		Subgraph: subgraphFingerprint(H, allowed),
+		Depth:    recDepth,
+		Child:    edgeNames(child, l.edgeName),
+		Accepted: accepted,
+	}
+	if parent.Len() > 0 {
+		decision.Parent = edgeNames(parent, l.edgeName)
+	}
+	l.Decisions.add(decision)
+}
+
+// replayed returns the accepted decisions of the replayed log for the subproblem, if any
+func (l *LogKDecomp) replayed(H lib.Graph, allowed lib.Edges) []Decision {
+	if l.replay == nil {
+		return nil
+	}
+	return l.replay[replayKey{width: l.K, subgraph: subgraphFingerprint(H, allowed)}]
+}
+
+// replayChildren returns an iterator over the children of the replayed decisions, followed by those of next
+func (l *LogKDecomp) replayChildren(decisions []Decision, allowed lib.Edges, next func() (lib.Edges, bool)) func() (lib.Edges, bool) {
+	var children []lib.Edges
+	seen := make(map[uint64]bool)
+	for _, decision := range decisions {
+		child, ok := edgesByName(allowed, decision.Child, l.edgeName)
+		if ok && !seen[child.Hash()] {
+			seen[child.Hash()] = true
+			children = append(children, child)
+		}
+	}
+
+	return func() (lib.Edges, bool) {
+		if len(children) == 0 {
+			return next()
+		}
+		child := children[0]
+		children = children[1:]
+		return child, true
+	}
+}
+
+// replayParents returns an iterator over the parents the replayed decisions chose above child, followed by
+// those of next. Recorded parents that don't satisfy pred are skipped, as the search relies on it.
+func (l *LogKDecomp) replayParents(decisions []Decision, H lib.Graph, child lib.Edges, allowedParent lib.Edges, pred lib.Predicate,
+	balFactor int, next func() (lib.Edges, bool)) func() (lib.Edges, bool) {
+	names := nameSet(edgeNames(child, l.edgeName))
+
+	var parents []lib.Edges
+	for _, decision := range decisions {
+		if len(decision.Parent) == 0 || nameSet(decision.Child) != names {
+			continue
+		}
+		parent, ok := edgesByName(allowedParent, decision.Parent, l.edgeName)
+		if ok && pred.Check(&H, &parent, balFactor) {
+			parents = append(parents, parent)
+		}
+	}
+
+	return func() (lib.Edges, bool) {
+		if len(parents) == 0 {
+			return next()
+		}
+		parent := parents[0]
+		parents = parents[1:]
+		return parent, true
+	}
+}
@@ -0,0 +1,59 @@
+package logk
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// RestoreError describes a reduction of the graph that could not be undone on its decomposition
+type RestoreError struct {
+	Reduction string   // the kind of reduction, either GYÖ or Type Collapse
+	Op        string   // the operation of the reduction that failed
+	Partial   lib.Node // the decomposition as restored up to the failing operation
+}
+
+func (e *RestoreError) Error() string {
+	return fmt.Sprintf("%s reduction failed at %s", e.Reduction, e.Op)
+}
+
+// Unwrap allows checking for ErrRestoreFailed via errors.Is
+func (e *RestoreError) Unwrap() error {
+	return ErrRestoreFailed
+}
+
+// RestoreGYÖ undoes the GYÖ reductions on a decomposition of the reduced graph, one at a time, s.t. a
+// failure can be attributed to the operation causing it
+func RestoreGYÖ(root lib.Node, ops []lib.GYÖReduct) (lib.Node, error) {
+	for _, op := range ops {
+		restored, ok := root.RestoreGYÖ([]lib.GYÖReduct{op})
+		if !ok {
+			return root, &RestoreError{Reduction: "GYÖ", Op: fmt.Sprint(op), Partial: root}
+		}
+		root = restored
+	}
+
+	return root, nil
+}
+
+// RestoreTypes undoes the Type Collapse on a decomposition of the collapsed graph, restoring the
+// collapsed vertices of each type in the order of their representatives
+func RestoreTypes(root lib.Node, removalMap map[int][]int) (lib.Node, error) {
+	var representatives []int
+	for v := range removalMap {
+		representatives = append(representatives, v)
+	}
+	sort.Ints(representatives)
+
+	for _, v := range representatives {
+		restored, ok := root.RestoreTypes(map[int][]int{v: removalMap[v]})
+		if !ok {
+			op := fmt.Sprintf("%v into %v", lib.PrintVertices(removalMap[v]), lib.PrintVertices([]int{v}))
+			return root, &RestoreError{Reduction: "Type Collapse", Op: op, Partial: root}
+		}
+		root = restored
+	}
+
+	return root, nil
+}
@@ -0,0 +1,66 @@
+package logk
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+func TestRestoreGYÖFailure(t *testing.T) {
+	// e1 lies within e2, and b then only lies on e2, so both get reduced
+	graph, _ := lib.GetGraph("e1(a,b), e2(a,b,c), e3(c,d), e4(d,a).")
+	reduced, ops := graph.GYÖReduct()
+	if len(ops) == 0 {
+		t.Fatalf("nothing got reduced in %v", graph)
+	}
+
+	var e3 lib.Edge
+	for _, e := range reduced.Edges.Slice() {
+		if lib.Subset(e.Vertices, graph.Edges.Slice()[2].Vertices) {
+			e3 = e
+		}
+	}
+
+	// no bag contains the remainder of e2, so neither vertex b nor e1 can be restored
+	partial := lib.Node{Bag: e3.Vertices, Cover: lib.NewEdges([]lib.Edge{e3})}
+	_, err := RestoreGYÖ(partial, ops)
+
+	var restoreErr *RestoreError
+	if !errors.As(err, &restoreErr) || !errors.Is(err, ErrRestoreFailed) {
+		t.Fatalf("got error %v, want a RestoreError", err)
+	}
+	if restoreErr.Reduction != "GYÖ" || restoreErr.Op == "" {
+		t.Errorf("got reduction %q at %q", restoreErr.Reduction, restoreErr.Op)
+	}
+	if !lib.Subset(restoreErr.Partial.Bag, partial.Bag) || len(restoreErr.Partial.Children) != 0 {
+		t.Errorf("got partial decomp %v, want the one before the failing operation", restoreErr.Partial)
+	}
+}
+
+func TestRestoreTypesFailure(t *testing.T) {
+	graph, _ := lib.GetGraph("e1(a,b,c), e2(c,d).")
+	vertices := graph.Edges.Slice()[0].Vertices // a, b, c in the order of the edge
+	a, b, c := vertices[0], vertices[1], vertices[2]
+
+	// b is restored into a, which isn't in any bag
+	root := lib.Node{Bag: []int{c}, Cover: graph.Edges}
+	_, err := RestoreTypes(root, map[int][]int{a: {b}})
+
+	var restoreErr *RestoreError
+	if !errors.As(err, &restoreErr) || !errors.Is(err, ErrRestoreFailed) {
+		t.Fatalf("got error %v, want a RestoreError", err)
+	}
+	if restoreErr.Reduction != "Type Collapse" {
+		t.Errorf("got reduction %q", restoreErr.Reduction)
+	}
+
+	// restoring into c works
+	restored, err := RestoreTypes(root, map[int][]int{c: {b}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lib.Subset([]int{b, c}, restored.Bag) {
+		t.Errorf("got bag %v, want b restored alongside c", lib.PrintVertices(restored.Bag))
+	}
+}
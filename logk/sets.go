@@ -0,0 +1,122 @@
+package logk
+
+import (
+	"sort"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// The set operations of lib compare every pair of elements. The vertex sets of graphs and edge sets are
+// sorted though, as are all sets derived from them via interSorted, so the hot path of the search uses
+// merges instead. All arguments called sorted must be sorted in increasing order, without duplicates.
+
+// interSorted computes the intersection of two sorted vertex sets, which is sorted as well
+func interSorted(as, bs []int) []int {
+	var output []int
+	for i, j := 0, 0; i < len(as) && j < len(bs); {
+		switch {
+		case as[i] < bs[j]:
+			i++
+		case as[i] > bs[j]:
+			j++
+		default:
+			output = append(output, as[i])
+			i++
+			j++
+		}
+	}
+
+	return output
+}
+
+// subsetSorted checks if the sorted vertex set as is contained in the sorted bs
+func subsetSorted(as, bs []int) bool {
+	j := 0
+	for _, a := range as {
+		for j < len(bs) && bs[j] < a {
+			j++
+		}
+		if j == len(bs) || bs[j] != a {
+			return false
+		}
+		j++
+	}
+
+	return true
+}
+
+// unionSorted computes the union of two sorted vertex sets, which is sorted as well. Unlike appending one
+// to the other, this never writes into the arrays backing the arguments.
+func unionSorted(as, bs []int) []int {
+	output := make([]int, 0, len(as)+len(bs))
+	i, j := 0, 0
+	for i < len(as) && j < len(bs) {
+		switch {
+		case as[i] < bs[j]:
+			output = append(output, as[i])
+			i++
+		case as[i] > bs[j]:
+			output = append(output, bs[j])
+			j++
+		default:
+			output = append(output, as[i])
+			i++
+			j++
+		}
+	}
+	output = append(output, as[i:]...)
+
+	return append(output, bs[j:]...)
+}
+
+// filterTouching keeps the edges sharing a vertex with the sorted vertex set, just like lib.FilterVertices
+func filterTouching(edges lib.Edges, sorted []int) lib.Edges {
+	var output []lib.Edge
+
+	for _, e := range edges.Slice() {
+		for _, v := range e.Vertices {
+			if i := sort.SearchInts(sorted, v); i < len(sorted) && sorted[i] == v {
+				output = append(output, e)
+				break
+			}
+		}
+	}
+
+	return lib.NewEdges(output)
+}
+
+// parentCheck is lib.ParentCheck, using the merges on sorted sets. Conn and Child have to be sorted.
+type parentCheck struct {
+	Conn  []int
+	Child []int
+}
+
+// Check performs the needed computation to ensure whether sep is a good parent
+func (p parentCheck) Check(H *lib.Graph, sep *lib.Edges, balFactor int) bool {
+	comps, _, _ := H.GetComponents(*sep)
+
+	foundCompLow := false
+	var compLow lib.Graph
+
+	balancednessLimit := (((H.Len()) * (balFactor - 1)) / balFactor)
+
+	for i := range comps {
+		if comps[i].Len() > balancednessLimit {
+			foundCompLow = true
+			compLow = comps[i]
+		}
+	}
+	if !foundCompLow {
+		return false
+	}
+
+	vertCompLow := compLow.Vertices()
+	childχ := interSorted(p.Child, vertCompLow)
+
+	if !subsetSorted(interSorted(vertCompLow, p.Conn), sep.Vertices()) {
+		return false
+	}
+
+	// Connectivity check
+	return subsetSorted(interSorted(vertCompLow, sep.Vertices()), childχ)
+}
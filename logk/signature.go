@@ -0,0 +1,70 @@
+package logk
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// NodeSignature computes a canonical description of the subtree rooted at n, built from the vertex sets of
+// its bags and covers. The order of children, of the edges in a cover and of the vertices in a bag don't
+// matter, so two subtrees have the same signature exactly if they are the same up to the order of children.
+// Edges are told apart by their vertices only, so a subedge equals any other edge on the same vertices.
+func NodeSignature(n lib.Node) string {
+	var buffer strings.Builder
+	writeSignature(&buffer, n)
+	return buffer.String()
+}
+
+func writeSignature(buffer *strings.Builder, n lib.Node) {
+	buffer.WriteString("(")
+	writeVertexSet(buffer, n.Bag)
+
+	covers := make([]string, 0, n.Cover.Len())
+	for _, e := range n.Cover.Slice() {
+		var edge strings.Builder
+		writeVertexSet(&edge, e.Vertices)
+		covers = append(covers, edge.String())
+	}
+	sort.Strings(covers)
+	buffer.WriteString(strings.Join(covers, ""))
+
+	children := make([]string, 0, len(n.Children))
+	for _, c := range n.Children {
+		children = append(children, NodeSignature(c))
+	}
+	sort.Strings(children)
+	buffer.WriteString(strings.Join(children, ""))
+	buffer.WriteString(")")
+}
+
+// writeVertexSet writes a set of vertices in increasing order, e.g. [1 4 7]
+func writeVertexSet(buffer *strings.Builder, vertices []int) {
+	sorted := append([]int{}, vertices...)
+	sort.Ints(sorted)
+
+	buffer.WriteString("[")
+	for i, v := range sorted {
+		if i > 0 {
+			buffer.WriteString(" ")
+		}
+		buffer.WriteString(strconv.Itoa(v))
+	}
+	buffer.WriteString("]")
+}
+
+// NodeHash hashes the signature of the subtree rooted at n, s.t. subtrees equal by EqualNodes hash equal
+func NodeHash(n lib.Node) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(NodeSignature(n)))
+	return h.Sum64()
+}
+
+// EqualNodes checks if two subtrees are the same up to the order of children, as defined by NodeSignature.
+// This is far cheaper than reflect.DeepEqual, and unlike it ignores the order of children and edges.
+func EqualNodes(a, b lib.Node) bool {
+	return NodeSignature(a) == NodeSignature(b)
+}
@@ -0,0 +1,45 @@
+package logk
+
+import (
+	"testing"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+func TestNodeSignature(t *testing.T) {
+	graph, _ := lib.GetGraph("e0(a,b), e1(b,c), e2(c,d), e3(d,a).")
+	e := graph.Edges.Slice()
+	node := func(bag []int, cover []lib.Edge, children ...lib.Node) lib.Node {
+		return lib.Node{Bag: bag, Cover: lib.NewEdges(cover), Children: children}
+	}
+	a, b, c, d := e[0].Vertices[0], e[0].Vertices[1], e[2].Vertices[0], e[2].Vertices[1]
+
+	left := node([]int{a, b}, []lib.Edge{e[0]})
+	right := node([]int{c, d}, []lib.Edge{e[2]})
+	tree := node([]int{a, b, c, d}, []lib.Edge{e[1], e[3]}, left, right)
+
+	// the same tree, with the children, the edges of the cover and the vertices of the bags reordered
+	reordered := node([]int{d, c, b, a}, []lib.Edge{e[3], e[1]},
+		node([]int{d, c}, []lib.Edge{e[2]}), node([]int{b, a}, []lib.Edge{e[0]}))
+
+	if !EqualNodes(tree, reordered) || NodeHash(tree) != NodeHash(reordered) {
+		t.Errorf("got different signatures for reordered trees:\n%s\n%s", NodeSignature(tree),
+			NodeSignature(reordered))
+	}
+
+	different := []struct {
+		name string
+		node lib.Node
+	}{
+		{"other bag", node([]int{a, b, c}, []lib.Edge{e[1], e[3]}, left, right)},
+		{"other cover", node([]int{a, b, c, d}, []lib.Edge{e[0], e[2]}, left, right)},
+		{"missing child", node([]int{a, b, c, d}, []lib.Edge{e[1], e[3]}, left)},
+		{"child moved down", node([]int{a, b, c, d}, []lib.Edge{e[1], e[3]},
+			node(left.Bag, left.Cover.Slice(), right))},
+	}
+	for _, tt := range different {
+		if EqualNodes(tree, tt.node) || NodeHash(tree) == NodeHash(tt.node) {
+			t.Errorf("%s: got the same signature %s", tt.name, NodeSignature(tt.node))
+		}
+	}
+}
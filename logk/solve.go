@@ -0,0 +1,134 @@
+package logk
+
+// solve.go runs the whole pipeline of the command line tool on a graph, i.e. ordering its edges, reducing
+// it, searching for a decomp and restoring it, and reports how the decomp came about
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// Options configures Solve
+type Options struct {
+	Graph        lib.Graph
+	Width        int             // the width to search for, ignored if Exact is set
+	Exact        bool            // search for the smallest width with a decomp instead
+	Heuristic    int             // the ordering of the edges, numbered like for ApplyHeuristic, 0 for none
+	TypeCollapse bool            // collapse vertices of the same type before the search
+	GYÖ          bool            // perform a GYÖ reduct before the search
+	BalFactor    int             // the balance factor of the search, 2 if unset
+	Context      context.Context // cancels the search, never cancelled if nil
+}
+
+// Timing is the time taken by one step of Solve
+type Timing struct {
+	Label    string // either Heuristic, Reductions or Decomposition
+	Duration time.Duration
+}
+
+// Result is a decomp found by Solve, along with how it was found
+type Result struct {
+	Decomp     lib.Decomp // the decomp of the input graph, empty if none was found
+	Width      int        // the width searched for, with Exact the smallest one for which a decomp was found
+	Heuristic  string     // the name of the ordering of the edges
+	Reductions []string   // the reductions applied to the graph before the search, in this order
+	Times      []Timing   // the time taken by each step, in the order they ran
+	Exact      bool       // whether Width is proven to be the smallest width of any decomp
+	Incomplete bool       // whether the search abandoned any recursive call, so a failure may be a false negative
+}
+
+// Total returns the sum of the times taken by all steps
+func (r Result) Total() time.Duration {
+	var output time.Duration
+	for _, t := range r.Times {
+		output += t.Duration
+	}
+	return output
+}
+
+// Solve searches for a decomp of the graph in opts, using LogKDecomp. If none is found, the result is returned
+// along with the error of FindDecompResult explaining why, e.g. ErrNoDecomposition.
+func Solve(opts Options) (Result, error) {
+	var result Result
+	if !opts.Exact && opts.Width <= 0 {
+		return result, ErrInvalidWidth
+	}
+	if opts.BalFactor == 0 {
+		opts.BalFactor = 2
+	}
+	if IsEmptyGraph(opts.Graph) { // the trivial decomp of width 0
+		result.Decomp = lib.Decomp{Graph: opts.Graph}
+		result.Exact = opts.Exact
+		return result, nil
+	}
+
+	start := time.Now()
+	graph, name, err := ApplyHeuristic(opts.Graph, opts.Heuristic)
+	if err != nil {
+		return result, err
+	}
+	result.Heuristic = name
+	result.Times = append(result.Times, Timing{Label: "Heuristic", Duration: time.Now().Sub(start)})
+
+	start = time.Now()
+	var removalMap map[int][]int
+	if opts.TypeCollapse {
+		graph, removalMap, _ = graph.TypeCollapse()
+		result.Reductions = append(result.Reductions, "Type Collapse")
+	}
+	var ops []lib.GYÖReduct
+	if opts.GYÖ {
+		graph, ops = graph.GYÖReduct()
+		result.Reductions = append(result.Reductions, "GYÖ")
+	}
+	result.Times = append(result.Times, Timing{Label: "Reductions", Duration: time.Now().Sub(start)})
+
+	start = time.Now()
+	solver := &LogKDecomp{Graph: graph, K: opts.Width, BalFactor: opts.BalFactor}
+	if opts.Context != nil {
+		solver.SetContext(opts.Context)
+	}
+
+	var decomp lib.Decomp
+	if opts.Exact {
+		// any graph has a decomp of width |E|, so the loop ends at the latest there
+		result.Exact = true
+		for K := 1; K <= graph.Edges.Len(); K++ {
+			solver.SetWidth(K)
+			decomp, err = solver.FindDecompResult()
+			result.Width = K
+			if errors.Is(err, ErrIncomplete) {
+				result.Exact, result.Incomplete = false, true
+				continue
+			}
+			if !errors.Is(err, ErrNoDecomposition) {
+				break
+			}
+		}
+	} else {
+		result.Width = opts.Width
+		decomp, err = solver.FindDecompResult()
+		result.Incomplete = errors.Is(err, ErrIncomplete)
+	}
+	result.Times = append(result.Times, Timing{Label: "Decomposition", Duration: time.Now().Sub(start)})
+	if err != nil {
+		result.Exact = false
+		return result, err
+	}
+
+	decomp.Root, err = RestoreGYÖ(decomp.Root, ops)
+	if err == nil {
+		decomp.Root, err = RestoreTypes(decomp.Root, removalMap)
+	}
+	if err != nil {
+		return result, err
+	}
+	decomp.Graph = opts.Graph
+	decomp.RestoreSubedges()
+	result.Decomp = decomp
+
+	return result, nil
+}
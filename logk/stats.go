@@ -0,0 +1,145 @@
+package logk
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// searchStats collects counters during a search, safe for concurrent use
+type searchStats struct {
+	negativeHits    int64
+	negativeInserts int64
+	positiveHits    int64
+	positiveInserts int64
+	calls           int64
+	children        int64
+	parents         int64
+	abandoned       int64
+	truncated       int64
+	reusedComps     int64
+	splitComps      int64
+}
+
+// Stats is a snapshot of the statistics collected during the searches of an algorithm
+type Stats struct {
+	NegativeHits    int64 // number of separators rejected due to the negative cache
+	NegativeInserts int64 // number of entries added to the negative cache
+	PositiveHits    int64 // number of subproblems solved via the positive cache
+	PositiveInserts int64 // number of entries added to the positive cache
+	Calls           int64 // number of recursive calls of the search
+	Children        int64 // number of child separators considered
+	Parents         int64 // number of parent separators considered
+	Abandoned       int64 // number of recursive calls abandoned after exceeding the sub-timeout
+	Truncated       int64 // number of recursive calls giving up after trying the maximal number of separators
+	ReusedComps     int64 // number of components of a child in H taken over for the low component of a parent
+	SplitComps      int64 // number of components of a child in H only partly inside the low component, split anew
+	CacheSize       int   // number of separators stored in the negative cache
+}
+
+func (s *searchStats) snapshot() Stats {
+	return Stats{
+		NegativeHits:    atomic.LoadInt64(&s.negativeHits),
+		NegativeInserts: atomic.LoadInt64(&s.negativeInserts),
+		PositiveHits:    atomic.LoadInt64(&s.positiveHits),
+		PositiveInserts: atomic.LoadInt64(&s.positiveInserts),
+		Calls:           atomic.LoadInt64(&s.calls),
+		Children:        atomic.LoadInt64(&s.children),
+		Parents:         atomic.LoadInt64(&s.parents),
+		Abandoned:       atomic.LoadInt64(&s.abandoned),
+		Truncated:       atomic.LoadInt64(&s.truncated),
+		ReusedComps:     atomic.LoadInt64(&s.reusedComps),
+		SplitComps:      atomic.LoadInt64(&s.splitComps),
+	}
+}
+
+func (s *searchStats) reset() {
+	atomic.StoreInt64(&s.negativeHits, 0)
+	atomic.StoreInt64(&s.negativeInserts, 0)
+	atomic.StoreInt64(&s.positiveHits, 0)
+	atomic.StoreInt64(&s.positiveInserts, 0)
+	atomic.StoreInt64(&s.calls, 0)
+	atomic.StoreInt64(&s.children, 0)
+	atomic.StoreInt64(&s.parents, 0)
+	atomic.StoreInt64(&s.abandoned, 0)
+	atomic.StoreInt64(&s.truncated, 0)
+	atomic.StoreInt64(&s.reusedComps, 0)
+	atomic.StoreInt64(&s.splitComps, 0)
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("Recursive calls: %d\nChild separators: %d\nParent separators: %d\nAbandoned calls: %d\nTruncated calls: %d\n"+
+		"Child components reused: %d\nChild components split anew: %d\n"+
+		"Negative cache hits: %d\nNegative cache inserts: %d\nPositive cache hits: %d\n"+
+		"Positive cache inserts: %d\nCache size: %d", s.Calls, s.Children, s.Parents, s.Abandoned, s.Truncated, s.ReusedComps,
+		s.SplitComps, s.NegativeHits, s.NegativeInserts, s.PositiveHits, s.PositiveInserts, s.CacheSize)
+}
+
+// BranchStats counts the calls of LogKHybrid taking one branch of its predicate, and the sizes of their subgraphs
+type BranchStats struct {
+	Calls    int64 // number of recursive calls taking the branch
+	MinEdges int   // smallest number of edges of their subgraphs
+	MaxEdges int   // largest number of edges of their subgraphs
+	SumEdges int64 // total number of edges of their subgraphs
+}
+
+func (b *BranchStats) add(edges int) {
+	if b.Calls == 0 || edges < b.MinEdges {
+		b.MinEdges = edges
+	}
+	if edges > b.MaxEdges {
+		b.MaxEdges = edges
+	}
+	b.Calls++
+	b.SumEdges += int64(edges)
+}
+
+func (b BranchStats) String() string {
+	if b.Calls == 0 {
+		return "0 calls"
+	}
+	return fmt.Sprintf("%d calls, edges of the subgraphs: min %d, avg %.1f, max %d", b.Calls, b.MinEdges,
+		float64(b.SumEdges)/float64(b.Calls), b.MaxEdges)
+}
+
+// HybridStats counts the decisions of the predicate of LogKHybrid, i.e. how often the recursive calls below a
+// subgraph kept using the parallel LogKDecomp, or switched to the sequential DetKDecomp
+type HybridStats struct {
+	LogK BranchStats // the predicate didn't fire, so the recursive calls stay parallel
+	DetK BranchStats // the predicate fired, so the recursive calls are sequential
+}
+
+// hybridStats collects the HybridStats during a search, safe for concurrent use
+type hybridStats struct {
+	mux   sync.Mutex
+	stats HybridStats
+}
+
+func (s *hybridStats) add(fired bool, edges int) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	if fired {
+		s.stats.DetK.add(edges)
+	} else {
+		s.stats.LogK.add(edges)
+	}
+}
+
+func (s *hybridStats) snapshot() HybridStats {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return s.stats
+}
+
+func (s *hybridStats) reset() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.stats = HybridStats{}
+}
+
+func (s HybridStats) String() string {
+	return fmt.Sprintf("Predicate not fired (LogK): %v\nPredicate fired (DetK): %v", s.LogK, s.DetK)
+}
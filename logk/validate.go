@@ -0,0 +1,66 @@
+package logk
+
+// validate.go checks the connectedness condition on finished decompositions, independently of lib
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// ConnectednessError describes a vertex whose nodes in a decomposition don't form a connected subtree
+type ConnectednessError struct {
+	Vertex int // the offending vertex
+	Parts  int // the number of disconnected subtrees formed by the nodes containing it
+}
+
+func (e *ConnectednessError) Error() string {
+	return fmt.Sprintf("vertex %s is contained in %d disconnected subtrees", lib.PrintVertices([]int{e.Vertex}), e.Parts)
+}
+
+// Unwrap allows checking for ErrNotConnected via errors.Is
+func (e *ConnectednessError) Unwrap() error {
+	return ErrNotConnected
+}
+
+// ValidateConnectedness checks that for every vertex, the nodes of the decomp whose bags contain it form a
+// connected subtree, reporting the smallest offending vertex as a ConnectednessError. Unlike Correct, it
+// checks nothing else, and works without the graph of the decomp, e.g. for decomps from elsewhere.
+//
+// The nodes containing a vertex are connected iff exactly one of them has a parent not containing it,
+// i.e. is the root of their subtree, which a single walk of the tree can count for all vertices at once.
+func ValidateConnectedness(d lib.Decomp) error {
+	if IsEmptyDecomp(d) {
+		return nil
+	}
+
+	tops := make(map[int]int)
+	var walk func(n lib.Node, parent map[int]bool)
+	walk = func(n lib.Node, parent map[int]bool) {
+		bag := make(map[int]bool, len(n.Bag))
+		for _, v := range n.Bag {
+			if !parent[v] && !bag[v] {
+				tops[v]++
+			}
+			bag[v] = true
+		}
+		for _, child := range n.Children {
+			walk(child, bag)
+		}
+	}
+	walk(d.Root, nil)
+
+	var broken []int
+	for v, count := range tops {
+		if count > 1 {
+			broken = append(broken, v)
+		}
+	}
+	if len(broken) == 0 {
+		return nil
+	}
+	sort.Ints(broken)
+
+	return &ConnectednessError{Vertex: broken[0], Parts: tops[broken[0]]}
+}
@@ -0,0 +1,51 @@
+package logk
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+func TestValidateConnectedness(t *testing.T) {
+	graph, _ := lib.GetGraph("e0(a,b), e1(b,c), e2(c,d).")
+	e := graph.Edges.Slice()
+	a, b, c, d := e[0].Vertices[0], e[0].Vertices[1], e[2].Vertices[0], e[2].Vertices[1]
+	node := func(bag []int, cover int, children ...lib.Node) lib.Node {
+		return lib.Node{Bag: bag, Cover: lib.NewEdges([]lib.Edge{e[cover]}), Children: children}
+	}
+
+	valid := node([]int{a, b}, 0, node([]int{b, c}, 1, node([]int{c, d}, 2)))
+	if err := ValidateConnectedness(lib.Decomp{Graph: graph, Root: valid}); err != nil {
+		t.Errorf("got error %v for a valid decomp", err)
+	}
+
+	tests := []struct {
+		name   string
+		root   lib.Node
+		vertex int
+	}{
+		{"skipping the middle node", node([]int{a, b}, 0, node([]int{c}, 1, node([]int{b, c, d}, 2))), b},
+		{"in two siblings only", node([]int{b, c}, 1, node([]int{a, b, d}, 0), node([]int{c, d}, 2)), d},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateConnectedness(lib.Decomp{Graph: graph, Root: tt.root})
+			var connErr *ConnectednessError
+			if !errors.As(err, &connErr) || !errors.Is(err, ErrNotConnected) {
+				t.Fatalf("got error %v, want a ConnectednessError", err)
+			}
+			if connErr.Vertex != tt.vertex || connErr.Parts != 2 {
+				t.Errorf("got vertex %s in %d parts, want %s in 2", lib.PrintVertices([]int{connErr.Vertex}),
+					connErr.Parts, lib.PrintVertices([]int{tt.vertex}))
+			}
+		})
+	}
+
+	// the decomps found by the search pass
+	found := (&LogKDecomp{Graph: gridGraph(3, 4), K: 2, BalFactor: 2}).FindDecomp()
+	if err := ValidateConnectedness(found); err != nil {
+		t.Errorf("got error %v for the decomp found:\n%v", err, found)
+	}
+}
@@ -0,0 +1,65 @@
+package logk
+
+import "github.com/cem-okulmus/BalancedGo/lib"
+
+// weightEpsilon absorbs rounding errors when comparing summed weights against the width
+const weightEpsilon = 1e-9
+
+// coverWeight sums up the weights of the edges in a cover, where edges without a weight weigh 1
+func coverWeight(cover lib.Edges, weights map[int]float64) float64 {
+	output := 0.0
+	for _, e := range cover.Slice() {
+		if w, ok := weights[e.Name]; ok {
+			output += w
+		} else {
+			output++
+		}
+	}
+	return output
+}
+
+// WeightedWidth returns the largest weight of the cover of any node in the decomp
+func WeightedWidth(decomp lib.Decomp, weights map[int]float64) float64 {
+	output := 0.0
+
+	current := []lib.Node{decomp.Root}
+	for len(current) > 0 {
+		var children []lib.Node
+		for _, n := range current {
+			if w := coverWeight(n.Cover, weights); w > output {
+				output = w
+			}
+			children = append(children, n.Children...)
+		}
+		current = children
+	}
+
+	return output
+}
+
+// fitsWidth checks if the weight of a cover is within the width
+func (l *LogKDecomp) fitsWidth(cover lib.Edges) bool {
+	if l.Weights == nil {
+		return cover.Len() <= l.K
+	}
+	return coverWeight(cover, l.Weights) <= float64(l.K)+weightEpsilon
+}
+
+// weightedCheck extends a predicate of the separator search to also reject separators that are too heavy
+type weightedCheck struct {
+	pred lib.Predicate
+	l    *LogKDecomp
+}
+
+// Check tests the weight first, as it is far cheaper than checking balancedness
+func (w weightedCheck) Check(H *lib.Graph, sep *lib.Edges, balancedFactor int) bool {
+	return w.l.fitsWidth(*sep) && w.pred.Check(H, sep, balancedFactor)
+}
+
+// weighted returns the predicate to use in separator searches, taking the weights into account if set
+func (l *LogKDecomp) weighted(pred lib.Predicate) lib.Predicate {
+	if l.Weights == nil {
+		return pred
+	}
+	return weightedCheck{pred: pred, l: l}
+}
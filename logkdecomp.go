@@ -5,28 +5,24 @@
 package main
 
 import (
-	"flag"
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
-	"reflect"
+	"os/signal"
 	"runtime"
 	"runtime/pprof"
+	"runtime/trace"
+	"strings"
 	"time"
 
 	"github.com/cem-okulmus/BalancedGo/lib"
+	"github.com/cem-okulmus/log-k-decomp/logk"
 )
 
-// Algorithm serves as the common interface of all hypergraph decomposition algorithms
-type Algorithm interface {
-	// A Name is useful to identify the individual algorithms in the result
-	Name() string
-	FindDecomp() lib.Decomp
-	FindDecompGraph(G lib.Graph) lib.Decomp
-	SetWidth(K int)
-}
-
 // Decomp used to improve readability
 type Decomp = lib.Decomp
 
@@ -54,6 +50,43 @@ func check(e error) {
 
 }
 
+// exit codes of the command line tool
+const (
+	exitError     = 1   // an internal error occurred during the search
+	exitIncorrect = 2   // the decomposition checked with -verify is not correct
+	exitTimeout   = 3   // the search ran out of time
+	exitMemory    = 4   // the search reached the memory limit set via -maxmem
+	exitInterrupt = 130 // the search was interrupted by the user
+)
+
+// progressInterval is the time between two progress reports of the search
+const progressInterval = 5 * time.Second
+
+// memoryInterval is the time between two samples of the heap, when the memory is limited via -maxmem
+const memoryInterval = 100 * time.Millisecond
+
+// resultOut receives the decomposition and its summary, while diagOut receives diagnostics such as times
+var (
+	resultOut io.Writer = os.Stdout
+	diagOut   io.Writer = os.Stdout
+)
+
+// atExit collects the functions that need to run before the program exits, e.g. to flush profiles
+var atExit []func()
+
+// exit runs all registered functions in atExit, before exiting with the given exit code
+func exit(code int) {
+	for i := len(atExit) - 1; i >= 0; i-- {
+		atExit[i]()
+	}
+	os.Exit(code)
+}
+
+// contextAlgorithm is an algorithm whose search can be cancelled via a context
+type contextAlgorithm interface {
+	SetContext(ctx context.Context)
+}
+
 type labelTime struct {
 	time  float64
 	label string
@@ -63,11 +96,14 @@ func (l labelTime) String() string {
 	return fmt.Sprintf("%s : %.5f ms", l.label, l.time)
 }
 
-func outputStanza(algorithm string, decomp Decomp, times []labelTime, graph Graph, gml string, K int, skipCheck bool) {
+func outputStanza(algorithm string, heuristic string, decomp Decomp, times []labelTime, graph Graph, outputs []decompOutput, K int, skipCheck bool) bool {
 	decomp.RestoreSubedges()
 
-	fmt.Println("Used algorithm: " + algorithm)
-	fmt.Println("Result ( ran with K =", K, ")\n", decomp)
+	fmt.Fprintln(resultOut, "Used algorithm: "+algorithm)
+	if len(heuristic) > 0 {
+		fmt.Fprintln(resultOut, "Used heuristic: "+heuristic)
+	}
+	fmt.Fprintln(resultOut, "Result ( ran with K =", K, ")\n", printNode(decomp.Root))
 
 	// Print the times
 	var sumTotal float64
@@ -75,281 +111,294 @@ func outputStanza(algorithm string, decomp Decomp, times []labelTime, graph Grap
 	for _, time := range times {
 		sumTotal = sumTotal + time.time
 	}
-	fmt.Printf("Time: %.5f ms\n", sumTotal)
+	fmt.Fprintf(diagOut, "Time: %.5f ms\n", sumTotal)
 
-	fmt.Println("Time Composition: ")
+	fmt.Fprintln(diagOut, "Time Composition: ")
 	for _, time := range times {
-		fmt.Println(time)
+		fmt.Fprintln(diagOut, time)
 	}
 
-	fmt.Println("\nWidth: ", decomp.CheckWidth())
+	fmt.Fprintln(resultOut, "\nWidth: ", decomp.CheckWidth())
 	var correct bool
 	if !skipCheck {
 		correct = decomp.Correct(graph)
+		fmt.Fprintln(resultOut, "Correct: ", correct)
 	} else {
 		correct = true
+		fmt.Fprintln(resultOut, "Correct:  correctness check SKIPPED, the decomp is not verified")
 	}
 
-	fmt.Println("Correct: ", correct)
-	if correct && len(gml) > 0 {
-		f, err := os.Create(gml)
-		check(err)
+	writeOutputs(decomp, correct, outputs)
 
-		defer f.Close()
-		f.WriteString(decomp.ToGML())
-		f.Sync()
-	}
+	return correct
 }
 
-func main() {
-
-	// ==============================================
-	// Command-Line Argument Parsing
-
-	flagSet := flag.NewFlagSet("", flag.ContinueOnError)
-	flagSet.SetOutput(ioutil.Discard)
-
-	// input flags
-	graphPath := flagSet.String("graph", "", "input (for format see hyperbench.dbai.tuwien.ac.at/downloads/manual.pdf)")
-	width := flagSet.Int("width", 0, "a positive, non-zero integer indicating the width of the HD to search for")
-	exact := flagSet.Bool("exact", false, "Compute exact width (width flag ignored)")
-	approx := flagSet.Int("approx", 0, "Compute approximated width and set a timeout in seconds (width flag ignored)")
-
-	// algorithms  flags
-	logK := flagSet.Bool("logk", false, "Use LogKDecomp algorithm")
-	logKHybrid := flagSet.Int("logkHybrid", 0, "Use DetK - LogK Hybrid algorithm. Choose which predicate to use")
-
-	// heuristic flags
-	heur := "1 ... Vertex Degree Ordering\n\t2 ... Max. Separator Ordering\n\t3 ... MCSO\n\t4 ... Edge Degree Ordering"
-	useHeuristic := flagSet.Int("heuristic", 0, "turn on to activate edge ordering\n\t"+heur)
-	gyö := flagSet.Bool("g", false, "perform a GYÖ reduct")
-	typeC := flagSet.Bool("t", false, "perform a Type Collapse")
-	hingeFlag := flagSet.Bool("h", false, "use hingeTree Optimization")
-
-	//other optional  flags
-	cpuprofile := flagSet.String("cpuprofile", "", "write cpu profile to file")
-	logging := flagSet.Bool("log", false, "turn on extensive logs")
-	balanceFactorFlag := flagSet.Int("balfactor", 2, "Changes the factor that balanced separator check uses, default 2")
-	numCPUs := flagSet.Int("cpu", -1, "Set number of CPUs to use")
-	bench := flagSet.Bool("bench", false, "Benchmark mode, reduces unneeded output (incompatible with -log flag)")
-	gml := flagSet.String("gml", "", "Output the produced decomposition into the specified gml file ")
-	pace := flagSet.Bool("pace", false, "Use PACE 2019 format for graphs (see pacechallenge.org/2019/htd/htd_format/)")
-	meta := flagSet.Int("meta", 0, "meta parameter for LogKHybrid")
+// outputQuiet prints a single line with the algorithm, the input and the width of the decomp
+func outputQuiet(algorithm string, input string, decomp Decomp, graph Graph, outputs []decompOutput, skipCheck bool) bool {
+	decomp.RestoreSubedges()
 
-	parseError := flagSet.Parse(os.Args[1:])
-	if parseError != nil {
-		fmt.Print("Parse Error:\n", parseError.Error(), "\n\n")
+	correct := true
+	if !skipCheck {
+		correct = decomp.Correct(graph)
+	} else {
+		fmt.Fprintf(os.Stderr, "Correctness check SKIPPED for %s, the decomp is not verified\n", input)
 	}
-
-	// Output usage message if graph and width not specified
-	if parseError != nil || *graphPath == "" || (*width <= 0 && !*exact && *approx == 0) {
-		out := fmt.Sprint("Usage of log-k-decomp:")
-		fmt.Fprintln(os.Stderr, out)
-		flagSet.VisitAll(func(f *flag.Flag) {
-			if f.Name != "width" && f.Name != "graph" && f.Name != "exact" && f.Name != "approx" {
-				return
-			}
-			s := fmt.Sprintf("%T", f.Value) // used to get type of flag
-			if s[6:len(s)-5] != "bool" {
-				fmt.Printf("  -%-10s \t<%s>\n", f.Name, s[6:len(s)-5])
-			} else {
-				fmt.Printf("  -%-10s \n", f.Name)
-			}
-			fmt.Println("\t" + f.Usage)
-		})
-
-		fmt.Println("\nAlgorithm Choice: ")
-		flagSet.VisitAll(func(f *flag.Flag) {
-			if f.Name != "logk" && f.Name != "logkHybrid" {
-				return
-			}
-			s := fmt.Sprintf("%T", f.Value) // used to get type of flag
-			if s[6:len(s)-5] != "bool" {
-				fmt.Printf("  -%-10s \t<%s>\n", f.Name, s[6:len(s)-5])
-			} else {
-				fmt.Printf("  -%-10s \n", f.Name)
-			}
-			fmt.Println("\t" + f.Usage)
-		})
-
-		fmt.Println("\nOptional Arguments: ")
-		flagSet.VisitAll(func(f *flag.Flag) {
-			if f.Name == "width" || f.Name == "graph" || f.Name == "exact" || f.Name == "approx" || f.Name == "logkHybrid" || f.Name == "logk" {
-				return
-			}
-			s := fmt.Sprintf("%T", f.Value) // used to get type of flag
-			if s[6:len(s)-5] != "bool" {
-				fmt.Printf("  -%-10s \t<%s>\n", f.Name, s[6:len(s)-5])
-			} else {
-				fmt.Printf("  -%-10s \n", f.Name)
-			}
-			fmt.Println("\t" + f.Usage)
-		})
-
-		return
+	if correct {
+		fmt.Fprintln(resultOut, algorithm, input, decomp.CheckWidth())
+	} else {
+		fmt.Fprintln(resultOut, algorithm, input, "FAIL")
 	}
+	writeOutputs(decomp, correct, outputs)
 
-	// END Command-Line Argument Parsing
-	// ==============================================
-
-	if *exact && (*approx > 0) {
-		fmt.Println("Cannot have exact and approx flags set at the same time. Make up your mind.")
-		return
-	}
+	return correct
+}
 
-	if *cpuprofile != "" {
-		f, err := os.Create(*cpuprofile)
-		if err != nil {
-			log.Fatal(err)
+// writeOutputs stores a correct decomp in all output files that were asked for
+func writeOutputs(decomp Decomp, correct bool, outputs []decompOutput) {
+	for _, out := range outputs {
+		if len(out.path) == 0 {
+			continue
+		}
+		if logk.IsEmptyDecomp(decomp) {
+			fmt.Fprintln(os.Stderr, "No decomposition found, not writing", out.path)
+		} else if correct {
+			out.write(decomp)
 		}
-		pprof.StartCPUProfile(f)
-
-		defer pprof.StopCPUProfile()
 	}
+}
 
-	if *bench { // no logging output when running benchmarks
-		*logging = false
+// verifyDecomp checks the decomposition in the given json or gml file against the graph, and returns the
+// exit code, which is exitIncorrect if it is not correct
+func verifyDecomp(path string, graph Graph) int {
+	dat, err := readInput(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not read the decomposition:", err)
+		return exitError
+	}
+	var decomp Decomp
+	if strings.HasSuffix(path, ".gml") {
+		decomp, err = fromGML(dat, graph)
+	} else {
+		decomp, err = fromJSON(dat, graph)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not parse the decomposition:", err)
+		return exitError
 	}
-	logActive(*logging)
-
-	BalFactor := *balanceFactorFlag
 
-	runtime.GOMAXPROCS(*numCPUs)
+	correct := decomp.Correct(graph)
+	fmt.Println("Width: ", decomp.CheckWidth())
+	fmt.Println("Correct: ", correct)
+	// checked once more, independently of lib, which also points at the offending vertex
+	if err := logk.ValidateConnectedness(decomp); err != nil {
+		fmt.Println("Connectedness: ", err)
+		correct = false
+	}
+	if !correct {
+		return exitIncorrect
+	}
+	return 0
+}
 
-	dat, err := ioutil.ReadFile(*graphPath)
-	check(err)
+// preparedGraph is an input graph made ready for the search, along with what it takes to turn the decomps
+// of the reduced graph back into decomps of the input
+type preparedGraph struct {
+	original      Graph // the graph as read from the input
+	graph         Graph // the graph left by the reductions, which gets searched
+	weights       map[int]float64
+	replay        *logk.DecisionLog
+	decisions     *logk.DecisionLog // the log written by -replaylog, if any
+	heuristicName string
+	times         []labelTime
+	removalMap    map[int][]int
+	ops           []lib.GYÖReduct
+	hinget        lib.Hingetree
+}
 
-	var parsedGraph Graph
+// prepareGraph reads the files accompanying the graph, such as the edge weights, and applies the heuristics
+// and reductions asked for. It returns the exit code, which is non-zero if any of it failed.
+func prepareGraph(opts *options, graph Graph, graphPath string) (*preparedGraph, int) {
+	input := &preparedGraph{original: graph, graph: graph}
 
-	if !*pace {
-		parsedGraph, _ = lib.GetGraph(string(dat))
-	} else {
-		parsedGraph = lib.GetGraphPACE(string(dat))
+	if opts.weightsFile != "" {
+		dat, err := readInput(opts.weightsFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Could not read the edge weights:", err)
+			return nil, exitError
+		}
+		input.weights, err = parseWeights(string(dat), graph)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Could not parse the edge weights:", err)
+			return nil, exitError
+		}
 	}
 
-	originalGraph := parsedGraph
-
-	if !*bench { // skip any output if bench flag is set
-		log.Println("BIP: ", parsedGraph.GetBIP())
+	if opts.replayFile != "" {
+		dat, err := readInput(opts.replayFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Could not read the replay log:", err)
+			return nil, exitError
+		}
+		input.replay, err = logk.ReadDecisionLog(bytes.NewReader(dat))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Could not parse the replay log:", err)
+			return nil, exitError
+		}
+		if n := input.replay.Ambiguous(); n > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: %d subproblems accepted more than one separator in the replay log, so the replay may end up with another decomp\n", n)
+		}
+	}
+	if opts.replayLog != "" {
+		input.decisions = &logk.DecisionLog{}
 	}
 
 	var reducedGraph Graph
 
-	var times []labelTime
+	// Removing duplicate edges, which the covers never need, as the bags covering the kept edges cover them
+	if opts.dedup {
+		duplicates := findDuplicates(input.graph, input.weights)
+		input.graph = dedupGraph(input.graph, duplicates)
+		if !opts.bench {
+			fmt.Print("Removed ", duplicates.count(), " duplicate edge(s), and repeated vertices from ",
+				len(duplicates.loops), " edge(s)\n\n")
+		}
+	}
 
 	// Sorting Edges to find separators faster
-	if *useHeuristic > 0 {
-		var heuristicMessage string
+	if len(opts.heuristics) > 0 {
+		input.heuristicName = heuristicsName(opts.heuristics)
+		heuristicMessage := "Using " + input.heuristicName + " as a heuristic"
 
 		start := time.Now()
-		switch *useHeuristic {
-		case 1:
-			parsedGraph.Edges = lib.GetDegreeOrder(parsedGraph.Edges)
-			heuristicMessage = "Using degree ordering as a heuristic"
-			break
-		case 2:
-			parsedGraph.Edges = lib.GetMaxSepOrder(parsedGraph.Edges)
-			heuristicMessage = "Using max separator ordering as a heuristic"
-			break
-		case 3:
-			parsedGraph.Edges = lib.GetMSCOrder(parsedGraph.Edges)
-			heuristicMessage = "Using MSC ordering as a heuristic"
-			break
-		case 4:
-			parsedGraph.Edges = lib.GetEdgeDegreeOrder(parsedGraph.Edges)
-			heuristicMessage = "Using edge degree ordering as a heuristic"
-			break
-		}
+		input.graph.Edges = applyHeuristics(input.graph.Edges, opts.heuristics)
 		d := time.Now().Sub(start)
 		msec := d.Seconds() * float64(time.Second/time.Millisecond)
-		times = append(times, labelTime{time: msec, label: "Heuristic"})
+		input.times = append(input.times, labelTime{time: msec, label: "Heuristic"})
 
-		if !*bench {
+		if !opts.bench {
 			fmt.Println(heuristicMessage)
 			fmt.Printf("Time for heuristic: %.5f ms\n", msec)
-			fmt.Printf("Ordering: %v\n", parsedGraph.String())
+			fmt.Printf("Ordering: %v\n", printGraph(input.graph))
 		}
 	}
-	var removalMap map[int][]int
 	// Performing Type Collapse
-	if *typeC {
+	if opts.typeC {
 		count := 0
-		reducedGraph, removalMap, count = parsedGraph.TypeCollapse()
-		parsedGraph = reducedGraph
-		if !*bench { // be silent when benchmarking
-			fmt.Println("\n\n", *graphPath)
+		reducedGraph, input.removalMap, count = input.graph.TypeCollapse()
+		input.graph = reducedGraph
+		if !opts.bench { // be silent when benchmarking
+			fmt.Println("\n\n", graphPath)
 			fmt.Println("Graph after Type Collapse:")
 			for _, e := range reducedGraph.Edges.Slice() {
-				fmt.Printf("%v %v\n", e, Edge{Vertices: e.Vertices})
+				fmt.Printf("%v %v\n", edgeName(e), printVertices(e.Vertices))
 			}
 			fmt.Print("Removed ", count, " vertex/vertices\n\n")
 		}
 	}
 
-	var ops []lib.GYÖReduct
 	// Performing GYÖ reduction
-	if *gyö {
+	if opts.gyö {
 
-		if *typeC {
-			reducedGraph, ops = reducedGraph.GYÖReduct()
+		if opts.typeC {
+			reducedGraph, input.ops = reducedGraph.GYÖReduct()
 		} else {
-			reducedGraph, ops = parsedGraph.GYÖReduct()
+			reducedGraph, input.ops = input.graph.GYÖReduct()
 		}
 
-		parsedGraph = reducedGraph
-		if !*bench { // be silent when benchmarking
+		input.graph = reducedGraph
+		if !opts.bench { // be silent when benchmarking
 			fmt.Println("Graph after GYÖ:")
-			fmt.Println(reducedGraph)
+			fmt.Println(printGraph(reducedGraph))
 			fmt.Println("Reductions:")
-			fmt.Print(ops, "\n\n")
+			reductions := make([]string, len(input.ops))
+			for i, op := range input.ops {
+				reductions[i] = printGYÖ(op)
+			}
+			fmt.Print("[", strings.Join(reductions, " "), "]\n\n")
 		}
 
 	}
 
-	var hinget lib.Hingetree
-	var msecHinge float64
+	if opts.dumpReducedFile != "" {
+		if err := dumpReduced(opts.dumpReducedFile, opts.format, input.graph, input.ops, input.removalMap); err != nil {
+			fmt.Fprintln(os.Stderr, "Could not write the reduced graph:", err)
+			return nil, exitError
+		}
+	}
 
-	if *hingeFlag {
+	if opts.hinge {
 		startHinge := time.Now()
 
-		hinget = lib.GetHingeTree(parsedGraph)
+		input.hinget = lib.GetHingeTree(input.graph)
 
 		dHinge := time.Now().Sub(startHinge)
-		msecHinge = dHinge.Seconds() * float64(time.Second/time.Millisecond)
-		times = append(times, labelTime{time: msecHinge, label: "Hingetree"})
+		msecHinge := dHinge.Seconds() * float64(time.Second/time.Millisecond)
+		input.times = append(input.times, labelTime{time: msecHinge, label: "Hingetree"})
 
-		if !*bench {
+		if !opts.bench {
 			fmt.Println("Produced Hingetree: ")
-			fmt.Println(hinget)
+			fmt.Println(input.hinget)
 		}
 	}
 
-	var solver Algorithm
+	return input, 0
+}
+
+// newLogK creates an instance of LogKDecomp for the given width, each with its own caches
+func newLogK(opts *options, input *preparedGraph, K int) *logk.LogKDecomp {
+	logK := &logk.LogKDecomp{
+		Graph:           input.graph,
+		K:               K,
+		BalFactor:       opts.balanceFactor,
+		MostBalanced:    opts.mostBalanced,
+		FullBalCheck:    opts.balCheck == "full",
+		NoPositiveCache: opts.noPositive,
+		MaxWorkers:      opts.maxWorkers,
+		Deterministic:   opts.deterministic || input.replay != nil, // s.t. the replay doesn't depend on the scheduling
+		GHD:             opts.ghd,
+		DepthLimit:      opts.maxDepth,
+		SubTimeout:      opts.subTimeout,
+		MaxCandidates:   opts.maxCandidates,
+		SearchSplit:     opts.searchSplit,
+		Weights:         input.weights,
+		Decisions:       input.decisions,
+		Replay:          input.replay,
+		EdgeName:        edgeName,
+	}
+	if opts.noCache {
+		logK.Cache = logk.NoCache{}
+	}
+	if opts.balStep > 0 {
+		logK.BalPolicy = logk.DepthBalance(opts.balanceFactor, opts.balStep)
+	}
+	return logK
+}
+
+// newSolver creates the algorithm chosen by the flags, along with the number of algorithms chosen, which
+// must not exceed one
+func newSolver(opts *options, input *preparedGraph) (logk.Algorithm, int) {
+	var solver logk.Algorithm
 
 	// Check for multiple flags
 	chosen := 0
 
-	if *logK {
-		logK := LogKDecomp{
-			Graph:     parsedGraph,
-			K:         *width,
-			BalFactor: BalFactor,
-		}
-		solver = &logK
+	if opts.logK {
+		solver = newLogK(opts, input, opts.width)
 		chosen++
 	}
 
-	if *logKHybrid > 0 {
-		logKHyb := LogKHybrid{
-			Graph:     parsedGraph,
-			K:         *width,
-			BalFactor: BalFactor,
+	if opts.logKHybrid > 0 {
+		logKHyb := logk.LogKHybrid{
+			Graph:        input.graph,
+			K:            opts.width,
+			BalFactor:    opts.balanceFactor,
+			CollectStats: opts.stats,
 		}
-		logKHyb.Size = *meta
+		logKHyb.Size = opts.meta
 
-		var pred HybridPredicate
+		var pred logk.HybridPredicate
 
-		switch *logKHybrid {
+		switch opts.logKHybrid {
 		case 1:
 			pred = logKHyb.NumberEdgesPred
 		case 2:
@@ -358,6 +407,10 @@ func main() {
 			pred = logKHyb.ETimesKDivAvgEdgePred
 		case 4:
 			pred = logKHyb.OneRoundPred
+		case 5:
+			pred = logKHyb.DepthPred
+		case 6:
+			pred = logKHyb.LogEdgesPred
 
 		}
 
@@ -367,47 +420,237 @@ func main() {
 		chosen++
 	}
 
+	return solver, chosen
+}
+
+// reportComponents decomposes each connected component of the graph separately, and reports their widths
+func reportComponents(solver logk.Algorithm, graph Graph) int {
+	start := time.Now()
+	results, err := decompComponents(solver, getConnectedComponents(graph))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	d := time.Now().Sub(start)
+	msec := d.Seconds() * float64(time.Second/time.Millisecond)
+
+	fmt.Println("Used algorithm: " + solver.Name())
+	outputComponents(results)
+	fmt.Printf("Time: %.5f ms\n", msec)
+
+	return 0
+}
+
+// decompGraph runs the whole pipeline on a single input graph, and returns the exit code
+func decompGraph(ctx context.Context, opts *options, graphPath string) int {
+	graph, err := readGraph(opts.format, graphPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not read the input graph:", err)
+		return exitError
+	}
+
+	if opts.specialFile != "" {
+		dat, err := readInput(opts.specialFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Could not read the special edges:", err)
+			return exitError
+		}
+		special, err := parseSpecial(string(dat), graph)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Could not parse the special edges:", err)
+			return exitError
+		}
+		graph = Graph{Edges: graph.Edges, Special: special} // drops the cached vertices
+	}
+
+	if opts.verify != "" {
+		return verifyDecomp(opts.verify, graph)
+	}
+
+	if opts.info {
+		printGraphInfo(os.Stdout, graph)
+		return 0
+	}
+
+	if opts.bounds {
+		printBounds(os.Stdout, graph, opts.ghd)
+		return 0
+	}
+
+	// the search treats a lack of edges as a failure, so the trivial decomp is reported right away
+	if logk.IsEmptyGraph(graph) {
+		if opts.quiet {
+			fmt.Fprintln(resultOut, "empty", graphPath, 0)
+		} else {
+			fmt.Fprintln(resultOut, "The input graph is empty, its trivial decomposition has width 0")
+			fmt.Fprintln(resultOut, "\nWidth: ", 0)
+			fmt.Fprintln(resultOut, "Correct: ", true)
+		}
+		return 0
+	}
+
+	input, code := prepareGraph(opts, graph, graphPath)
+	if code != 0 {
+		return code
+	}
+
+	solver, chosen := newSolver(opts, input)
 	if chosen > 1 {
 		fmt.Println("Only one algorithm may be chosen at a time. Make up your mind.")
+		return 0
+	}
+	if solver == nil {
+		fmt.Println("No algorithm or procedure selected.")
+		return 0
+	}
+
+	if opts.components {
+		return reportComponents(solver, input.graph)
+	}
+
+	return searchGraph(ctx, opts, input, solver, graphPath)
+}
+
+func main() {
+
+	// ==============================================
+	// Command-Line Argument Parsing
+
+	opts := &options{}
+	flagSet := newFlagSet(opts)
+
+	parseError := flagSet.Parse(os.Args[1:])
+	if parseError != nil {
+		fmt.Print("Parse Error:\n", parseError.Error(), "\n\n")
+	}
+
+	if parseError == nil && opts.configFile != "" {
+		dat, err := readInput(opts.configFile)
+		if err == nil {
+			err = applyConfig(flagSet, dat)
+		}
+		if err != nil {
+			fmt.Println("Could not load the config:", err)
+			return
+		}
+	}
+
+	// Output usage message if graph and width not specified
+	if parseError != nil || opts.missingInput() {
+		printUsage(flagSet)
 		return
 	}
 
-	if solver != nil {
+	// END Command-Line Argument Parsing
+	// ==============================================
 
-		var decomp Decomp
-		start := time.Now()
+	if err := opts.validate(); err != nil {
+		fmt.Println(err)
+		return
+	}
 
-		if *hingeFlag {
-			decomp = hinget.DecompHinge(solver, parsedGraph)
-		} else {
-			decomp = solver.FindDecomp()
+	if opts.cpuprofile != "" {
+		f, err := os.Create(opts.cpuprofile)
+		if err != nil {
+			log.Fatal(err)
 		}
+		pprof.StartCPUProfile(f)
 
-		d := time.Now().Sub(start)
-		msec := d.Seconds() * float64(time.Second/time.Millisecond)
-		times = append(times, labelTime{time: msec, label: "Decomposition"})
-
-		if !reflect.DeepEqual(decomp, Decomp{}) || (len(ops) > 0 && parsedGraph.Edges.Len() == 0) {
-			var result bool
-			decomp.Root, result = decomp.Root.RestoreGYÖ(ops)
-			if !result {
-				fmt.Println("Partial decomp:", decomp.Root)
-				log.Panicln("GYÖ reduction failed")
-			}
-			decomp.Root, result = decomp.Root.RestoreTypes(removalMap)
-			if !result {
-				fmt.Println("Partial decomp:", decomp.Root)
-				log.Panicln("Type Collapse reduction failed")
-			}
+		defer pprof.StopCPUProfile()
+		atExit = append(atExit, pprof.StopCPUProfile)
+	}
+
+	if opts.memprofile != "" {
+		f, err := os.Create(opts.memprofile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		writeHeapProfile := func() {
+			runtime.GC() // get up-to-date statistics
+			pprof.WriteHeapProfile(f)
+			f.Close()
 		}
 
-		if !reflect.DeepEqual(decomp, Decomp{}) {
-			decomp.Graph = originalGraph
+		defer writeHeapProfile()
+		atExit = append(atExit, writeHeapProfile)
+	}
+
+	if opts.traceFile != "" {
+		f, err := os.Create(opts.traceFile)
+		if err != nil {
+			log.Fatal(err)
 		}
-		outputStanza(solver.Name(), decomp, times, originalGraph, *gml, *width, false)
+		trace.Start(f)
 
-		return
+		defer trace.Stop()
+		atExit = append(atExit, trace.Stop)
+	}
+
+	if opts.outFile != "" {
+		f, err := os.Create(opts.outFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		defer f.Close()
+		atExit = append(atExit, func() { f.Close() })
+		resultOut = f
+		diagOut = os.Stderr
+	}
+
+	streamOut = opts.streamOut
+
+	if opts.quiet { // quiet mode skips even more output than benchmarks
+		opts.bench = true
 	}
 
-	fmt.Println("No algorithm or procedure selected.")
+	logLevel := logk.LogError
+	if opts.logLevelName != "" {
+		var err error
+		logLevel, err = logk.ParseLogLevel(opts.logLevelName)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	if opts.logging {
+		logLevel = logk.LogTrace
+	}
+
+	if opts.bench { // no logging output when running benchmarks
+		opts.logging = false
+		opts.logLevelName = ""
+		logLevel = logk.LogError
+	}
+	logActive(opts.logging || opts.logLevelName != "")
+	logk.SetLogLevel(logLevel)
+
+	runtime.GOMAXPROCS(opts.numCPUs)
+
+	// stop the search gracefully on the first interrupt, and right away on the second one
+	rootCtx, cancelRoot := context.WithCancel(context.Background())
+	defer cancelRoot()
+
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	go func() {
+		<-interrupts
+		fmt.Fprintln(os.Stderr, "Interrupted, stopping the search. Interrupt again to exit right away.")
+		cancelRoot()
+		<-interrupts
+		fmt.Println("interrupted")
+		exit(exitInterrupt)
+	}()
+
+	decomp := func(graphPath string) int {
+		return decompGraph(rootCtx, opts, graphPath)
+	}
+
+	if opts.dir != "" {
+		exit(decompDir(opts.dir, opts.quiet, decomp))
+	}
+
+	if code := decomp(opts.graphPath); code != 0 {
+		exit(code)
+	}
 }
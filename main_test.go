@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runMainEnv marks the test binary as running the command line tool instead of the tests
+const runMainEnv = "LOGK_TEST_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// runMain runs the command line tool with args in a separate process, since it exits on its own, and
+// returns what it wrote to stdout and stderr, along with its exit code
+func runMain(t *testing.T, args ...string) (stdout, stderr string, code int) {
+	t.Helper()
+
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), runMainEnv+"=1")
+	var outBuf, errBuf bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &outBuf, &errBuf
+
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		code = exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatalf("running %v: %v", args, err)
+	}
+
+	return outBuf.String(), errBuf.String(), code
+}
+
+func TestMaxDepthDisconnected(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"components joined", nil},
+		{"components reported", []string{"-components"}},
+		{"hinges", []string{"-h"}},
+		{"hinges in parallel", []string{"-h", "-hingeparallel", "2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"-graph", "testdata/disconnected.hg", "-logk", "-width", "2", "-maxdepth", "1"},
+				tt.args...)
+			_, stderr, code := runMain(t, args...)
+
+			if code != exitError {
+				t.Errorf("got exit code %d, want %d", code, exitError)
+			}
+			if !strings.Contains(stderr, "recursion depth limit exceeded") || strings.Contains(stderr, "panic") {
+				t.Errorf("want the depth limit reported without a panic, got:\n%s", stderr)
+			}
+		})
+	}
+}
+
+func TestImprovementReported(t *testing.T) {
+	// the 3x4 grid is of hypertree width 2
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"width", []string{"-width", "2"}},
+		{"exact", []string{"-exact"}},
+		{"approx", []string{"-approx", "10"}},
+		{"refine", []string{"-width", "3", "-refine"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, code := runMain(t, append([]string{"-graph", "testdata/grid3x4.hg", "-logk"}, tt.args...)...)
+			if code != 0 {
+				t.Fatalf("got exit code %d:\n%s", code, stderr)
+			}
+			if !strings.Contains(stdout, "Found width 2 at") {
+				t.Errorf("want width 2 reported as found, got:\n%s", stdout)
+			}
+		})
+	}
+}
+
+func TestImprovementNotReportedPerComponent(t *testing.T) {
+	// the components are of widths 2 and 1, neither of which is a width found for the whole graph at once
+	stdout, stderr, code := runMain(t, "-graph", "testdata/twowidths.hg", "-logk", "-approx", "10")
+	if code != 0 {
+		t.Fatalf("got exit code %d:\n%s", code, stderr)
+	}
+	if strings.Contains(stdout, "Found width") {
+		t.Errorf("want no widths of single components reported, got:\n%s", stdout)
+	}
+	if !strings.Contains(stdout, "Approximated width:  2") {
+		t.Errorf("want width 2 approximated, got:\n%s", stdout)
+	}
+}
+
+func TestExactRejectsWidth(t *testing.T) {
+	const rejection = "Cannot have exact and width flags set at the same time"
+
+	stdout, stderr, code := runMain(t, "-graph", "testdata/grid3x4.hg", "-logk", "-exact", "-width", "2")
+	if code != 0 {
+		t.Fatalf("got exit code %d:\n%s", code, stderr)
+	}
+	if !strings.Contains(stdout, rejection) || strings.Contains(stdout, "Width:") {
+		t.Errorf("want the flags rejected before any search, got:\n%s", stdout)
+	}
+
+	// either flag on its own is fine
+	for _, args := range [][]string{{"-exact"}, {"-width", "2"}} {
+		stdout, _, _ := runMain(t, append([]string{"-graph", "testdata/grid3x4.hg", "-logk"}, args...)...)
+		if strings.Contains(stdout, rejection) || !strings.Contains(stdout, "Width:  2") {
+			t.Errorf("%v: want a decomp of width 2, got:\n%s", args, stdout)
+		}
+	}
+}
+
+func TestTimeoutKeepsDecomp(t *testing.T) {
+	// the widths from 13 on succeed right away, while the smaller ones keep searching until the timeout
+	stdout, stderr, code := runMain(t, "-graph", "testdata/random60.hg", "-logk", "-exact", "-exactparallel", "12",
+		"-timeout", "1")
+
+	if code != 0 {
+		t.Fatalf("got exit code %d, want 0:\n%s%s", code, stdout, stderr)
+	}
+	if !strings.Contains(stdout, "Correct:  true\n") || strings.Contains(stdout, "timed out") {
+		t.Errorf("want the decomp found before the timeout, got:\n%s", stdout)
+	}
+}
+
+func TestEmptyInput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logk-empty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name    string
+		content string
+		format  string
+		code    int
+	}{
+		{"empty file", "", "default", 0},
+		{"comments only", "% no edges\n", "default", 0},
+		{"PACE", "p htd 0 0\n", "pace", 0},
+		{"DIMACS", "p edge 0 0\n", "dimacs", 0},
+		{"PACE without problem line", "", "pace", exitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "graph")
+			if err := ioutil.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			stdout, stderr, code := runMain(t, "-graph", path, "-format", tt.format, "-logk", "-width", "2")
+			if code != tt.code {
+				t.Fatalf("got exit code %d, want %d:\n%s%s", code, tt.code, stdout, stderr)
+			}
+			if tt.code == 0 && !strings.Contains(stdout, "Width:  0\nCorrect:  true\n") {
+				t.Errorf("want the trivial decomp of width 0, got:\n%s", stdout)
+			}
+		})
+	}
+}
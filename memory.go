@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// watchMemory samples the heap every interval, until done is closed. Once the heap gets within a tenth of
+// limit bytes, even right after a garbage collection, it sets reached and calls cancel, s.t. the search
+// stops before the process gets killed. The caches of the search make up most of the heap, but none of
+// them is bounded, so there is nothing to evict instead.
+func watchMemory(limit uint64, interval time.Duration, reached *int32, cancel context.CancelFunc,
+	done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	threshold := limit - limit/10
+	var stats runtime.MemStats
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			runtime.ReadMemStats(&stats)
+			if stats.HeapAlloc < threshold {
+				continue
+			}
+			runtime.GC() // garbage doesn't count towards the limit
+			runtime.ReadMemStats(&stats)
+			if stats.HeapAlloc >= threshold {
+				atomic.StoreInt32(reached, 1)
+				cancel()
+				return
+			}
+		}
+	}
+}
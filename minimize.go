@@ -0,0 +1,38 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// minimizeCovers greedily removes the edges from the cover of each node that aren't needed to cover its
+// bag, trying the edges covering the fewest vertices of the bag first. This keeps the decomposition
+// correct, as bags stay covered and smaller covers can't violate the special condition. Returns the
+// minimized tree and the number of removed edges.
+func minimizeCovers(n lib.Node) (lib.Node, int) {
+	cover := append([]lib.Edge{}, n.Cover.Slice()...)
+	sort.SliceStable(cover, func(i, j int) bool {
+		return len(lib.Inter(cover[i].Vertices, n.Bag)) < len(lib.Inter(cover[j].Vertices, n.Bag))
+	})
+
+	removed := 0
+	for i := 0; i < len(cover); {
+		without := lib.NewEdges(append(append([]lib.Edge{}, cover[:i]...), cover[i+1:]...))
+		if lib.Subset(n.Bag, without.Vertices()) {
+			cover = without.Slice()
+			removed++
+			continue
+		}
+		i++
+	}
+
+	output := lib.Node{Bag: n.Bag, Cover: lib.NewEdges(cover)}
+	for _, c := range n.Children {
+		child, removedChild := minimizeCovers(c)
+		output.Children = append(output.Children, child)
+		removed += removedChild
+	}
+
+	return output, removed
+}
@@ -0,0 +1,145 @@
+package main
+
+// names.go prints the vertices and edges of the input graph by the names they have in the input. lib keeps
+// the names of the graphs it parses to itself, so graphs read by readPACE are named here instead.
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// paceNames names the edges and vertices of a graph read by readPACE. Edge i+1 is named E<edges[i]>, and
+// vertex len(edges)+1+i is named V<vertices[i]>.
+type paceNames struct {
+	edges    []int // the names of the edges, in the order they appear
+	vertices []int // the vertices, in the order they first appear
+}
+
+// name returns the name of the edge or vertex encoded as x
+func (p *paceNames) name(x int) string {
+	if x >= 1 && x <= len(p.edges) {
+		return "E" + strconv.Itoa(p.edges[x-1])
+	}
+	if i := x - len(p.edges) - 1; i >= 0 && i < len(p.vertices) {
+		return "V" + strconv.Itoa(p.vertices[i])
+	}
+	return ""
+}
+
+// inputNames names the input graph if it was read by readPACE, and is nil if lib parsed and named it
+var inputNames *paceNames
+
+// vertexName returns the original name of a vertex, as found in the input graph
+func vertexName(v int) string {
+	if inputNames != nil {
+		return inputNames.name(v)
+	}
+	s := lib.PrintVertices([]int{v})
+	return strings.TrimSuffix(strings.TrimPrefix(s, "("), ")")
+}
+
+// vertexNames returns the original names of a list of vertices
+func vertexNames(vertices []int) []string {
+	names := make([]string, len(vertices))
+	for i, v := range vertices {
+		names[i] = vertexName(v)
+	}
+	return names
+}
+
+// edgeName returns the original name of an edge, as found in the input graph. Edges without a name, such as
+// special edges, are given by their vertices instead.
+func edgeName(e Edge) string {
+	if e.Name <= 0 {
+		return printVertices(e.Vertices)
+	}
+	if inputNames != nil {
+		return inputNames.name(e.Name)
+	}
+	return e.String()
+}
+
+// printVertices prints a list of vertices like lib.PrintVertices, e.g. (a, b)
+func printVertices(vertices []int) string {
+	return "(" + strings.Join(vertexNames(vertices), ", ") + ")"
+}
+
+// printEdges prints a set of edges like lib.Edges, e.g. {R, S}
+func printEdges(edges lib.Edges) string {
+	names := make([]string, 0, edges.Len())
+	for _, e := range edges.Slice() {
+		names = append(names, edgeName(e))
+	}
+	return "{" + strings.Join(names, ", ") + "}"
+}
+
+// printGraph prints a graph like lib.Graph, i.e. its edges followed by its special edges, if any
+func printGraph(g Graph) string {
+	output := printEdges(g.Edges)
+	if len(g.Special) > 0 {
+		special := make([]string, len(g.Special))
+		for i, sp := range g.Special {
+			special[i] = printEdges(sp)
+		}
+		output += " & Special Edges [" + strings.Join(special, ", ") + " ]"
+	}
+	return output
+}
+
+// printNode prints the subtree rooted at n like lib.Node, with the bag and cover of each node on a line
+// of their own, and the children indented below
+func printNode(n lib.Node) string {
+	var buffer bytes.Buffer
+	writeNode(&buffer, n, 0)
+	return buffer.String()
+}
+
+func writeNode(buffer *bytes.Buffer, n lib.Node, depth int) {
+	indent := strings.Repeat("\t", depth)
+
+	buffer.WriteString("\n" + indent + "Bag: {" + strings.Join(vertexNames(n.Bag), ", ") + "}")
+	buffer.WriteString("\n" + indent + "Cover: " + printEdges(n.Cover) + "\n")
+	if len(n.Children) > 0 {
+		buffer.WriteString(indent + "Children: " + strconv.Itoa(len(n.Children)) + "\n" + indent + "[")
+		for _, c := range n.Children {
+			writeNode(buffer, c, depth+1)
+		}
+		buffer.WriteString(indent + "]\n")
+	}
+}
+
+// printGYÖ prints a GYÖ reduction like lib, e.g. (R ⊆ S) for an edge R removed as a subedge of S, or
+// (a ∈ R) for a vertex a removed from R, the only edge containing it. The reductions keep their contents
+// to themselves, and only lib can name them, so for graphs read by readPACE they are looked up here.
+func printGYÖ(op lib.GYÖReduct) string {
+	if inputNames == nil {
+		return fmt.Sprint(op)
+	}
+
+	value := reflect.ValueOf(op)
+	if value.Kind() != reflect.Struct {
+		return fmt.Sprint(op)
+	}
+	if subedge, parent := value.FieldByName("subedge"), value.FieldByName("parent"); subedge.IsValid() && parent.IsValid() {
+		return "(" + edgeName(reflectEdge(subedge)) + " ⊆ " + edgeName(reflectEdge(parent)) + ")"
+	}
+	if vertex, edge := value.FieldByName("vertex"), value.FieldByName("edge"); vertex.IsValid() && edge.IsValid() {
+		return "(" + vertexName(int(vertex.Int())) + " ∈ " + edgeName(reflectEdge(edge)) + ")"
+	}
+	return fmt.Sprint(op)
+}
+
+// reflectEdge reads an edge held by a GYÖ reduction
+func reflectEdge(value reflect.Value) Edge {
+	vertices := value.FieldByName("Vertices")
+	output := Edge{Name: int(value.FieldByName("Name").Int()), Vertices: make([]int, vertices.Len())}
+	for i := range output.Vertices {
+		output.Vertices[i] = int(vertices.Index(i).Int())
+	}
+	return output
+}
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// options holds the settings of a run of the command line tool, as given by its flags
+type options struct {
+	// input flags
+	graphPath string
+	width     int
+	exact     bool
+	approx    int
+
+	// algorithms flags
+	logK       bool
+	logKHybrid int
+
+	// heuristic flags
+	useHeuristic    string
+	seed            int64
+	gyö             bool
+	typeC           bool
+	hinge           bool
+	dumpReducedFile string
+	dedup           bool
+
+	// other optional flags
+	cpuprofile    string
+	outFile       string
+	memprofile    string
+	traceFile     string
+	timeout       int
+	showRoot      bool
+	info          bool
+	bounds        bool
+	logging       bool
+	logLevelName  string
+	balanceFactor int
+	balCheck      string
+	balStep       int
+	numCPUs       int
+	bench         bool
+	quiet         bool
+	gml           string
+	jsonOut       string
+	dot           string
+	tdOut         string
+	refine        bool
+	skipCheck     bool
+	streamOut     bool
+	format        string
+	pace          bool
+	meta          int
+	strategy      string
+	lookahead     int
+	mostBalanced  int
+	noCache       bool
+	noPositive    bool
+	maxWorkers    int
+	searchSplit   int
+	deterministic bool
+	subTimeout    time.Duration
+	specialFile   string
+	weightsFile   string
+	minimize      bool
+	exactParallel int
+	hingeParallel int
+	replayLog     string
+	replayFile    string
+	maxMem        int
+	maxCandidates int
+	maxDepth      int
+	progress      bool
+	stats         bool
+	ghd           bool
+	fhtw          bool
+	csvOut        string
+	repeat        int
+	dir           string
+	components    bool
+	verify        string
+
+	configFile string
+
+	heuristics []heuristic // the orderings of -heuristic, set by validate
+}
+
+// newFlagSet defines the flags of the command line tool, storing their values in opts
+func newFlagSet(opts *options) *flag.FlagSet {
+	flagSet := flag.NewFlagSet("", flag.ContinueOnError)
+	flagSet.SetOutput(ioutil.Discard)
+
+	// input flags
+	flagSet.StringVar(&opts.graphPath, "graph", "", "input (for format see hyperbench.dbai.tuwien.ac.at/downloads/manual.pdf), use - to read from stdin")
+	flagSet.IntVar(&opts.width, "width", 0, "a positive, non-zero integer indicating the width of the HD to search for")
+	flagSet.BoolVar(&opts.exact, "exact", false, "Compute exact width (cannot be combined with -width)")
+	flagSet.IntVar(&opts.approx, "approx", 0, "Compute approximated width and set a timeout in seconds, narrowing the decomposition found from an upper bound until a width fails or the time runs out (width flag ignored)")
+
+	// algorithms  flags
+	flagSet.BoolVar(&opts.logK, "logk", false, "Use LogKDecomp algorithm")
+	flagSet.IntVar(&opts.logKHybrid, "logkHybrid", 0, "Use DetK - LogK Hybrid algorithm. Choose which predicate to use")
+
+	// heuristic flags
+	heur := "1 ... Vertex Degree Ordering\n\t2 ... Max. Separator Ordering\n\t3 ... MCSO\n\t4 ... Edge Degree Ordering\n\t5 ... Random Ordering"
+	flagSet.StringVar(&opts.useHeuristic, "heuristic", "0", "turn on to activate edge ordering, a comma-separated list applies\n\tfurther orderings to break ties of the previous ones\n\t"+heur)
+	flagSet.Int64Var(&opts.seed, "seed", 0, "seed used by the random ordering heuristic")
+	flagSet.BoolVar(&opts.gyö, "g", false, "perform a GYÖ reduct")
+	flagSet.BoolVar(&opts.typeC, "t", false, "perform a Type Collapse")
+	flagSet.BoolVar(&opts.hinge, "h", false, "use hingeTree Optimization")
+	flagSet.StringVar(&opts.dumpReducedFile, "dumpreduced", "", "Write the graph left by the reductions (-t, -g, -dedup) into the specified file, in the format of the input, listing the reductions to undo on its decomps in comments")
+	flagSet.BoolVar(&opts.dedup, "dedup", false, "remove duplicate edges, i.e. those on the same vertices as an earlier one, and repeated vertices inside edges")
+
+	//other optional  flags
+	flagSet.StringVar(&opts.cpuprofile, "cpuprofile", "", "write cpu profile to file")
+	flagSet.StringVar(&opts.outFile, "out", "", "Write the decomposition and its summary to the specified file, diagnostics go to stderr")
+	flagSet.StringVar(&opts.memprofile, "memprofile", "", "write memory profile to file")
+	flagSet.StringVar(&opts.traceFile, "trace", "", "write execution trace to file")
+	flagSet.IntVar(&opts.timeout, "timeout", 0, "Set a timeout in seconds for the entire decomposition")
+	flagSet.BoolVar(&opts.showRoot, "showroot", false, "Print the root node of the decomposition and the separators chosen by the root call of the search")
+	flagSet.BoolVar(&opts.info, "info", false, "Only print metrics of the input graph, like its number of edges and its BIP, without decomposing it")
+	flagSet.BoolVar(&opts.bounds, "bounds", false, "Only print a lower and an upper bound on the width of the input graph (with -ghd on the generalized width), without decomposing it")
+	flagSet.BoolVar(&opts.logging, "log", false, "turn on extensive logs, same as -loglevel trace")
+	flagSet.StringVar(&opts.logLevelName, "loglevel", "", "Log to stderr up to this level: error, info, debug or trace")
+	flagSet.IntVar(&opts.balanceFactor, "balfactor", 2, "Changes the factor that balanced separator check uses, must be at least 2, default 2")
+	flagSet.StringVar(&opts.balCheck, "balcheck", "fast", "Check the balancedness of separators via fast (lib.BalancedCheckFast) or full (logk.FullBalancedCheck, slower, comparing the vertices of special edges exactly) (LogKDecomp only)")
+	flagSet.IntVar(&opts.balStep, "balstep", 0, "Increase the balance factor by this much with each level of the recursion, loosening it towards the leaves (LogKDecomp only)")
+	flagSet.IntVar(&opts.numCPUs, "cpu", -1, "Set number of CPUs to use")
+	flagSet.BoolVar(&opts.bench, "bench", false, "Benchmark mode, reduces unneeded output (incompatible with -log flag)")
+	flagSet.BoolVar(&opts.quiet, "quiet", false, "Only print a single line with the algorithm, the input and the width (or FAIL/TIMEOUT)")
+	flagSet.StringVar(&opts.gml, "gml", "", "Output the produced decomposition into the specified gml file, in the format of BalancedGo, which can read it back")
+	flagSet.StringVar(&opts.jsonOut, "json", "", "Output the produced decomposition into the specified json file")
+	flagSet.StringVar(&opts.dot, "dot", "", "Output the produced decomposition into the specified dot file")
+	flagSet.StringVar(&opts.tdOut, "tdout", "", "Output the produced decomposition into the specified file, in the PACE 2019 .td format")
+	flagSet.BoolVar(&opts.refine, "refine", false, "Once a decomposition is found, keep searching for ones of smaller widths, until one fails or the timeout fires")
+	flagSet.BoolVar(&opts.skipCheck, "skipcheck", false, "Skip checking the correctness of the decomposition found, which is expensive for huge graphs")
+	flagSet.BoolVar(&opts.streamOut, "streamout", false, "Write the -gml, -dot and -tdout files while walking the decomposition, instead of building them in memory first")
+	flagSet.StringVar(&opts.format, "format", "default", "Format of the input graphs: "+formatNames()+" (for pace see pacechallenge.org/2019/htd/htd_format/)")
+	flagSet.BoolVar(&opts.pace, "pace", false, "Deprecated alias for -format pace")
+	flagSet.IntVar(&opts.meta, "meta", 0, "meta parameter for LogKHybrid")
+	flagSet.StringVar(&opts.strategy, "strategy", "first", "Strategy to select child separators: first (take the first balanced one) or lookahead (LogKDecomp only)")
+	flagSet.IntVar(&opts.lookahead, "lookahead", 4, "Number of balanced child separators evaluated by the lookahead strategy")
+	flagSet.IntVar(&opts.mostBalanced, "mostbalanced", 0, "Size of the window of balanced separators from which the most balanced one is chosen (LogKDecomp only)")
+	flagSet.BoolVar(&opts.noCache, "nocache", false, "Turn off the caching of separators known to fail (LogKDecomp only)")
+	flagSet.BoolVar(&opts.noPositive, "nopositive", false, "Turn off the caching of subproblems known to have a decomp (LogKDecomp only)")
+	flagSet.IntVar(&opts.maxWorkers, "maxworkers", 0, "Limit the number of concurrently running recursive calls (LogKDecomp only)")
+	flagSet.IntVar(&opts.searchSplit, "searchsplit", 0, "Split each separator search into this many parallel generators, instead of one per CPU (LogKDecomp only)")
+	flagSet.BoolVar(&opts.deterministic, "deterministic", false, "Search sequentially, always producing the same decomp for the same input. This may be slower (LogKDecomp only)")
+	flagSet.DurationVar(&opts.subTimeout, "subtimeout", 0, "Abandon any recursive call running longer than this (e.g. 500ms), possibly missing decomps (LogKDecomp only)")
+	flagSet.StringVar(&opts.specialFile, "special", "", "Read initial special edges from the specified file, one per line listing its vertices, e.g. \"a, b, c\"")
+	flagSet.StringVar(&opts.weightsFile, "weights", "", "Read edge weights from the specified file, one \"<edge> <weight>\" per line, and bound their sum in each cover by the width (LogKDecomp only)")
+	flagSet.BoolVar(&opts.minimize, "minimize", false, "Remove edges from the covers of the produced decomposition that aren't needed to cover their bags")
+	flagSet.IntVar(&opts.exactParallel, "exactparallel", 0, "With -exact, search up to this many widths concurrently, each with its own caches (LogKDecomp only)")
+	flagSet.IntVar(&opts.hingeParallel, "hingeparallel", 0, "With -h, decompose up to this many hinges concurrently, each with its own caches (LogKDecomp only)")
+	flagSet.StringVar(&opts.replayLog, "replaylog", "", "Write every separator tried by the search, and whether it led to a decomp, into the specified file, one JSON object per line (LogKDecomp only)")
+	flagSet.StringVar(&opts.replayFile, "replay", "", "Replay the decisions of a log written by -replaylog, searching sequentially to reconstruct its decomp up to the order of children (LogKDecomp only)")
+	flagSet.IntVar(&opts.maxMem, "maxmem", 0, "Stop the search once the heap approaches this many MB, reporting the best decomp found so far")
+	flagSet.IntVar(&opts.maxCandidates, "maxcandidates", 0, "Give up on a subproblem after trying this many separators, possibly missing decomps (LogKDecomp only)")
+	flagSet.IntVar(&opts.maxDepth, "maxdepth", 0, "Stop the search with an error once it exceeds the specified recursion depth (LogKDecomp only)")
+	flagSet.BoolVar(&opts.progress, "progress", false, "Periodically report the progress of the search")
+	flagSet.BoolVar(&opts.stats, "stats", false, "Print statistics about the search (LogKDecomp only), the decisions of the hybrid predicate (LogKHybrid only) and the width profile of the decomposition")
+	flagSet.BoolVar(&opts.ghd, "ghd", false, "Compute a generalized hypertree decomposition, which need not satisfy the special condition of HDs (LogKDecomp only)")
+	flagSet.BoolVar(&opts.fhtw, "fhtw", false, "Additionally report the fractional width of the produced decomposition, i.e. the maximal fractional edge cover of any bag")
+	flagSet.StringVar(&opts.csvOut, "csv", "", "Append a row with the timings of the run to the specified csv file")
+	flagSet.IntVar(&opts.repeat, "repeat", 0, "Run the search this many times, resetting the caches in between, and report the spread of the decomposition times and whether the width was the same in every run")
+	flagSet.StringVar(&opts.dir, "dir", "", "Decompose every graph in the specified directory, one after the other")
+	flagSet.BoolVar(&opts.components, "components", false, "Decompose each connected component separately and report their widths")
+	flagSet.StringVar(&opts.verify, "verify", "", "Check the decomposition in the specified json file (or gml file, as written by -gml or BalancedGo) against the graph, without running any search")
+
+	flagSet.StringVar(&opts.configFile, "config", "", "Load the settings from the specified JSON file, mapping flag names to values, e.g. {\"logk\": true, \"balfactor\": 3}. Explicit flags override the file")
+
+	return flagSet
+}
+
+// missingInput reports whether the flags lack an input graph, or anything to do with it
+func (o *options) missingInput() bool {
+	return (o.graphPath == "" && o.dir == "") ||
+		(o.width <= 0 && !o.exact && o.approx == 0 && o.verify == "" && !o.info && !o.bounds)
+}
+
+// printUsage prints the flags of the command line tool, starting with the input and the algorithms
+func printUsage(flagSet *flag.FlagSet) {
+	out := fmt.Sprint("Usage of log-k-decomp:")
+	fmt.Fprintln(os.Stderr, out)
+	flagSet.VisitAll(func(f *flag.Flag) {
+		if f.Name != "width" && f.Name != "graph" && f.Name != "exact" && f.Name != "approx" {
+			return
+		}
+		s := fmt.Sprintf("%T", f.Value) // used to get type of flag
+		if s[6:len(s)-5] != "bool" {
+			fmt.Printf("  -%-10s \t<%s>\n", f.Name, s[6:len(s)-5])
+		} else {
+			fmt.Printf("  -%-10s \n", f.Name)
+		}
+		fmt.Println("\t" + f.Usage)
+	})
+
+	fmt.Println("\nAlgorithm Choice: ")
+	flagSet.VisitAll(func(f *flag.Flag) {
+		if f.Name != "logk" && f.Name != "logkHybrid" {
+			return
+		}
+		s := fmt.Sprintf("%T", f.Value) // used to get type of flag
+		if s[6:len(s)-5] != "bool" {
+			fmt.Printf("  -%-10s \t<%s>\n", f.Name, s[6:len(s)-5])
+		} else {
+			fmt.Printf("  -%-10s \n", f.Name)
+		}
+		fmt.Println("\t" + f.Usage)
+	})
+
+	fmt.Println("\nOptional Arguments: ")
+	flagSet.VisitAll(func(f *flag.Flag) {
+		if f.Name == "width" || f.Name == "graph" || f.Name == "exact" || f.Name == "approx" || f.Name == "logkHybrid" || f.Name == "logk" {
+			return
+		}
+		s := fmt.Sprintf("%T", f.Value) // used to get type of flag
+		if s[6:len(s)-5] != "bool" {
+			fmt.Printf("  -%-10s \t<%s>\n", f.Name, s[6:len(s)-5])
+		} else {
+			fmt.Printf("  -%-10s \n", f.Name)
+		}
+		fmt.Println("\t" + f.Usage)
+	})
+}
+
+// validate rejects combinations of flags that make no sense, and resolves the flags standing in for others,
+// i.e. -pace, the lookahead strategy and the list of heuristics. The error is meant to be shown as is.
+func (o *options) validate() error {
+	if o.refine && o.exact {
+		return errors.New("The flag -refine cannot be combined with -exact, whose first decomposition found is of the smallest width already")
+	}
+
+	if o.exact && (o.approx > 0) {
+		return errors.New("Cannot have exact and approx flags set at the same time. Make up your mind.")
+	}
+
+	if o.approx < 0 {
+		return fmt.Errorf("The flag -approx needs a positive timeout in seconds. Got: %d", o.approx)
+	}
+
+	if o.refine && o.approx > 0 {
+		return errors.New("The flag -refine cannot be combined with -approx, which keeps narrowing the decompositions found already")
+	}
+
+	if o.exact && o.width > 0 {
+		return errors.New("Cannot have exact and width flags set at the same time, the exact search tries the widths itself.")
+	}
+
+	switch o.strategy {
+	case "first":
+	case "lookahead":
+		if o.lookahead < 2 {
+			return fmt.Errorf("The lookahead strategy needs to evaluate at least 2 children. Got: %d", o.lookahead)
+		}
+		o.mostBalanced = o.lookahead
+	default:
+		return fmt.Errorf("Unknown strategy: %s", o.strategy)
+	}
+
+	if o.weightsFile != "" && !o.logK {
+		return errors.New("The flag -weights requires -logk")
+	}
+
+	if o.specialFile != "" && (o.gyö || o.typeC || o.hinge) {
+		return errors.New("The flag -special cannot be combined with -g, -t or -h, as these ignore special edges")
+	}
+
+	// the bounds ignore special edges, e.g. a path closed into a cycle by one would be taken for acyclic
+	if o.specialFile != "" && (o.approx > 0 || o.bounds) {
+		return errors.New("The flag -special cannot be combined with -approx or -bounds, as their bounds ignore special edges")
+	}
+
+	if o.searchSplit < 0 {
+		return errors.New("The flag -searchsplit requires a positive number of generators")
+	}
+
+	if o.exactParallel < 0 || (o.exactParallel > 0 && (!o.exact || !o.logK)) {
+		return errors.New("The flag -exactparallel requires a positive number of widths, -exact and -logk")
+	}
+
+	if o.hingeParallel < 0 || (o.hingeParallel > 0 && (!o.hinge || !o.logK)) {
+		return errors.New("The flag -hingeparallel requires a positive number of hinges, -h and -logk")
+	}
+
+	if (o.replayLog != "" || o.replayFile != "") && !o.logK {
+		return errors.New("The flags -replaylog and -replay require -logk")
+	}
+
+	if o.maxMem < 0 {
+		return errors.New("The flag -maxmem requires a positive number of MB")
+	}
+
+	if o.repeat < 0 || (o.repeat > 0 && (o.replayLog != "" || o.components)) {
+		return errors.New("The flag -repeat requires a positive number of runs, and cannot be combined with -replaylog, which would log all runs, or -components")
+	}
+
+	if o.pace {
+		o.format = "pace"
+	}
+	if _, ok := graphFormats[o.format]; !ok {
+		return fmt.Errorf("Unknown input format: %s", o.format)
+	}
+
+	if o.balCheck != "fast" && o.balCheck != "full" {
+		return fmt.Errorf("Unknown balancedness check: %s", o.balCheck)
+	}
+	if o.balCheck == "full" && !o.logK {
+		return errors.New("The flag -balcheck full requires -logk")
+	}
+
+	if o.balStep < 0 {
+		return fmt.Errorf("The flag -balstep cannot be negative, as the balance factor must be at least 2. Got: %d", o.balStep)
+	}
+
+	if o.balanceFactor < 2 {
+		return fmt.Errorf("The balance factor must be at least 2, as otherwise no separator is balanced. Got: %d", o.balanceFactor)
+	}
+
+	heuristics, err := parseHeuristics(o.useHeuristic, o.seed)
+	if err != nil {
+		return fmt.Errorf("Invalid heuristic: %v", err)
+	}
+	o.heuristics = heuristics
+
+	return nil
+}
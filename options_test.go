@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// parseOptions parses the flags as main does, failing the test on flags it doesn't know
+func parseOptions(t *testing.T, args ...string) *options {
+	t.Helper()
+
+	opts := &options{}
+	if err := newFlagSet(opts).Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return opts
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string // the start of the error, empty if the flags are valid
+	}{
+		{[]string{"-logk", "-width", "2"}, ""},
+		{[]string{"-logk", "-exact", "-exactparallel", "2"}, ""},
+		{[]string{"-logk", "-exact", "-refine"}, "The flag -refine cannot be combined with -exact"},
+		{[]string{"-logk", "-exact", "-approx", "5"}, "Cannot have exact and approx flags set"},
+		{[]string{"-logk", "-approx", "-1"}, "The flag -approx needs a positive timeout"},
+		{[]string{"-logk", "-exact", "-width", "2"}, "Cannot have exact and width flags set"},
+		{[]string{"-logk", "-width", "2", "-strategy", "lookahead", "-lookahead", "1"}, "The lookahead strategy needs"},
+		{[]string{"-logk", "-width", "2", "-strategy", "best"}, "Unknown strategy: best"},
+		{[]string{"-logkHybrid", "1", "-width", "2", "-weights", "w"}, "The flag -weights requires -logk"},
+		{[]string{"-logk", "-width", "2", "-special", "s", "-h"}, "The flag -special cannot be combined with -g"},
+		{[]string{"-logk", "-approx", "5", "-special", "s"}, "The flag -special cannot be combined with -approx"},
+		{[]string{"-logk", "-width", "2", "-exactparallel", "2"}, "The flag -exactparallel requires"},
+		{[]string{"-logk", "-width", "2", "-hingeparallel", "2"}, "The flag -hingeparallel requires"},
+		{[]string{"-logkHybrid", "1", "-width", "2", "-replay", "r"}, "The flags -replaylog and -replay require -logk"},
+		{[]string{"-logk", "-width", "2", "-repeat", "2", "-components"}, "The flag -repeat requires"},
+		{[]string{"-logk", "-width", "2", "-format", "csv"}, "Unknown input format: csv"},
+		{[]string{"-logk", "-width", "2", "-balcheck", "slow"}, "Unknown balancedness check: slow"},
+		{[]string{"-logk", "-width", "2", "-balfactor", "1"}, "The balance factor must be at least 2"},
+		{[]string{"-logk", "-width", "2", "-heuristic", "9"}, "Invalid heuristic"},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			err := parseOptions(t, tt.args...).validate()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("got the error %q, want none", err)
+			case tt.want != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.want)):
+				t.Errorf("got the error %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateResolvesAliases(t *testing.T) {
+	opts := parseOptions(t, "-logk", "-width", "2", "-pace", "-strategy", "lookahead", "-lookahead", "3",
+		"-heuristic", "1,5")
+	if err := opts.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if opts.format != "pace" {
+		t.Errorf("-pace: got the format %q, want pace", opts.format)
+	}
+	if opts.mostBalanced != 3 {
+		t.Errorf("-lookahead: got a window of %d separators, want 3", opts.mostBalanced)
+	}
+	if len(opts.heuristics) != 2 {
+		t.Errorf("-heuristic: got %d heuristics, want 2", len(opts.heuristics))
+	}
+}
@@ -0,0 +1,388 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+	"github.com/cem-okulmus/log-k-decomp/logk"
+)
+
+// streamOut makes the output formats supporting it write the decomposition while walking its tree, rather
+// than building the whole output in memory first
+var streamOut = false
+
+// decompOutput writes a decomposition into a file, using the given output format. If the format can be
+// streamed, stream writes the same output as format.
+type decompOutput struct {
+	path   string
+	format func(decomp Decomp) string
+	stream func(w io.Writer, decomp Decomp)
+}
+
+// write creates the file at path and stores the formatted decomposition in it
+func (o decompOutput) write(decomp Decomp) {
+	f, err := os.Create(o.path)
+	check(err)
+
+	defer f.Close()
+	if streamOut && o.stream != nil {
+		w := bufio.NewWriter(f)
+		o.stream(w, decomp)
+		check(w.Flush())
+	} else {
+		f.WriteString(o.format(decomp))
+	}
+	f.Sync()
+}
+
+type jsonEdge struct {
+	Name     string   `json:"name"`
+	Vertices []string `json:"vertices"`
+}
+
+type jsonNode struct {
+	Bag      []string   `json:"bag"`
+	Cover    []jsonEdge `json:"cover"`
+	Children []jsonNode `json:"children"`
+}
+
+type jsonDecomp struct {
+	Width int      `json:"width"`
+	Root  jsonNode `json:"root"`
+}
+
+func toJSONNode(n lib.Node) jsonNode {
+	out := jsonNode{
+		Bag:      vertexNames(n.Bag),
+		Cover:    []jsonEdge{},
+		Children: []jsonNode{},
+	}
+
+	for _, e := range n.Cover.Slice() {
+		out.Cover = append(out.Cover, jsonEdge{Name: edgeName(e), Vertices: vertexNames(e.Vertices)})
+	}
+	for _, c := range n.Children {
+		out.Children = append(out.Children, toJSONNode(c))
+	}
+
+	return out
+}
+
+// toJSON serializes the tree of a decomposition, using the original names of vertices and edges
+func toJSON(decomp Decomp) string {
+	out, err := json.MarshalIndent(jsonDecomp{
+		Width: decomp.CheckWidth(),
+		Root:  toJSONNode(decomp.Root),
+	}, "", "  ")
+	check(err)
+
+	return string(out) + "\n"
+}
+
+// jsonDecoder maps the names used in a JSON decomposition back to the vertices and edges of a graph
+type jsonDecoder struct {
+	vertices map[string]int
+	edges    map[string]Edge
+}
+
+func newJSONDecoder(graph Graph) jsonDecoder {
+	d := jsonDecoder{vertices: make(map[string]int), edges: make(map[string]Edge)}
+
+	for _, v := range graph.Vertices() {
+		d.vertices[vertexName(v)] = v
+	}
+	for _, e := range graph.Edges.Slice() {
+		d.edges[edgeName(e)] = e
+	}
+
+	return d
+}
+
+func (d jsonDecoder) decodeVertices(names []string) ([]int, error) {
+	vertices := make([]int, len(names))
+	for i, name := range names {
+		v, ok := d.vertices[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown vertex %q", name)
+		}
+		vertices[i] = v
+	}
+	return vertices, nil
+}
+
+func (d jsonDecoder) decodeNode(n jsonNode) (lib.Node, error) {
+	bag, err := d.decodeVertices(n.Bag)
+	if err != nil {
+		return lib.Node{}, err
+	}
+
+	var cover []Edge
+	for _, e := range n.Cover {
+		if edge, ok := d.edges[e.Name]; ok {
+			cover = append(cover, edge)
+			continue
+		}
+		// not an edge of the graph, so keep it as the set of vertices it covers
+		vertices, err := d.decodeVertices(e.Vertices)
+		if err != nil {
+			return lib.Node{}, fmt.Errorf("edge %q: %v", e.Name, err)
+		}
+		cover = append(cover, Edge{Vertices: vertices})
+	}
+
+	node := lib.Node{Bag: bag, Cover: lib.NewEdges(cover)}
+	for _, c := range n.Children {
+		child, err := d.decodeNode(c)
+		if err != nil {
+			return lib.Node{}, err
+		}
+		node.Children = append(node.Children, child)
+	}
+
+	return node, nil
+}
+
+// fromJSON reads a decomposition of the given graph, as written by toJSON
+func fromJSON(data []byte, graph Graph) (Decomp, error) {
+	var in jsonDecomp
+	if err := json.Unmarshal(data, &in); err != nil {
+		return Decomp{}, err
+	}
+	if len(in.Root.Bag) == 0 && len(in.Root.Cover) == 0 && len(in.Root.Children) == 0 {
+		return Decomp{}, errors.New("decomposition is empty")
+	}
+
+	root, err := newJSONDecoder(graph).decodeNode(in.Root)
+	if err != nil {
+		return Decomp{}, err
+	}
+
+	return Decomp{Graph: graph, Root: root}, nil
+}
+
+// fromGML reads a decomposition of the given graph in the GML format of Decomp.ToGML, using the parser
+// of BalancedGo, s.t. decomps written by either tool can be checked by the other
+func fromGML(data []byte, graph Graph) (decomp Decomp, err error) {
+	encoding := make(map[string]int)
+	for _, v := range graph.Vertices() {
+		encoding[vertexName(v)] = v
+	}
+	for _, e := range graph.Edges.Slice() {
+		encoding[edgeName(e)] = e.Name
+	}
+
+	defer func() { // the parser panics on malformed input
+		if r := recover(); r != nil {
+			decomp, err = Decomp{}, errors.New(strings.TrimSpace(fmt.Sprint(r)))
+		}
+	}()
+
+	return lib.GetDecompGML(string(data), graph, encoding), nil
+}
+
+// toDOT exports the tree of a decomposition in the DOT format of Graphviz, labelling each node with
+// its cover and bag
+func toDOT(decomp Decomp) string {
+	var buffer bytes.Buffer
+	writeDOT(&buffer, decomp)
+
+	return buffer.String()
+}
+
+// writeDOT writes the output of toDOT while walking the tree
+func writeDOT(w io.Writer, decomp Decomp) {
+	io.WriteString(w, "graph decomp {\n  node [shape=box];\n\n")
+	num := 0
+	nodeToDOT(decomp.Root, &num, w)
+	io.WriteString(w, "}\n")
+}
+
+// nodeToDOT writes the subtree rooted at n, numbering the nodes in preorder, and returns the id of n
+func nodeToDOT(n lib.Node, num *int, w io.Writer) int {
+	id := *num
+	*num++
+
+	label := printEdges(n.Cover) + " " + printVertices(n.Bag)
+	fmt.Fprintf(w, "  n%d [label=%q];\n", id, label)
+
+	for _, c := range n.Children {
+		child := nodeToDOT(c, num, w)
+		fmt.Fprintf(w, "  n%d -- n%d;\n", id, child)
+	}
+
+	return id
+}
+
+// toGML exports the tree of a decomposition in the GML format of Decomp.ToGML
+func toGML(decomp Decomp) string {
+	var buffer bytes.Buffer
+	writeGML(&buffer, decomp)
+
+	return buffer.String()
+}
+
+// writeGML writes the tree of a decomposition in the GML format of Decomp.ToGML while walking it, first
+// the nodes, numbered in preorder, and then the edges between them
+func writeGML(w io.Writer, decomp Decomp) {
+	io.WriteString(w, "graph [\n\n  directed 0\n\n")
+	num := 0
+	nodesToGML(decomp.Root, &num, w)
+	num = 0
+	edgesToGML(decomp.Root, &num, w)
+	io.WriteString(w, "\n]\n")
+}
+
+// gmlBraces replaces parentheses just like Decomp.ToGML, to match the GML output of DetK
+var gmlBraces = strings.NewReplacer("(", "{", ")", "}")
+
+// nodesToGML writes the nodes of the subtree rooted at n, numbering them in preorder starting from 1
+func nodesToGML(n lib.Node, num *int, w io.Writer) {
+	*num++
+	label := gmlBraces.Replace(printEdges(n.Cover) + " " + printVertices(n.Bag))
+	fmt.Fprintf(w, "  node [\n    id %d\n    label \"%s\"\n    vgj [\n      labelPosition \"in\"\n      shape \"Rectangle\"\n    ]\n  ]\n\n",
+		*num, label)
+
+	for _, c := range n.Children {
+		nodesToGML(c, num, w)
+	}
+}
+
+// edgesToGML writes the edges of the subtree rooted at n, whose nodes are numbered as by nodesToGML
+func edgesToGML(n lib.Node, num *int, w io.Writer) {
+	*num++
+	id := *num
+
+	for _, c := range n.Children {
+		fmt.Fprintf(w, "  edge [\n    source %d\n    target %d\n  ]\n\n", id, *num+1)
+		edgesToGML(c, num, w)
+	}
+}
+
+// paceEncoding numbers the vertices of a graph for the PACE format. Graphs read with -pace keep their
+// original numbering, all others are numbered in the order of appearance, just like Graph.ToPACE. It reports
+// whether the vertices got renumbered.
+func paceEncoding(graph Graph) (map[int]int, bool) {
+	encoding := make(map[int]int)
+
+	original := true
+	for _, v := range graph.Vertices() {
+		name := vertexName(v)
+		n, err := strconv.Atoi(strings.TrimPrefix(name, "V"))
+		if err != nil || !strings.HasPrefix(name, "V") {
+			original = false
+			break
+		}
+		encoding[v] = n
+	}
+	if original {
+		return encoding, false
+	}
+
+	encoding = make(map[int]int)
+	counter := 1
+	for _, e := range graph.Edges.Slice() {
+		for _, v := range e.Vertices {
+			if _, ok := encoding[v]; !ok {
+				encoding[v] = counter
+				counter++
+			}
+		}
+	}
+
+	return encoding, true
+}
+
+// writePACENames writes a comment line "c <number> <name>" for each renumbered vertex, in the order of
+// their numbers, s.t. the bags can be matched back to the vertices of the input
+func writePACENames(w io.Writer, encoding map[int]int) {
+	names := make([]string, len(encoding)+1)
+	for v, n := range encoding {
+		names[n] = vertexName(v)
+	}
+	for n := 1; n < len(names); n++ {
+		fmt.Fprintf(w, "c %d %s\n", n, names[n])
+	}
+}
+
+// writePACE writes the output of toPACE while walking the tree. The header comes first, so the tree is
+// walked once for the header, once for the bags and once more for the edges between them.
+func writePACE(w io.Writer, decomp Decomp) {
+	encoding, renumbered := paceEncoding(decomp.Graph)
+	num, maxBag := 0, 0
+	nodeToPACE(decomp.Root, encoding, &num, &maxBag, ioutil.Discard, ioutil.Discard)
+	if renumbered {
+		writePACENames(w, encoding)
+	}
+	fmt.Fprintf(w, "s td %d %d %d\n", num, maxBag, len(encoding))
+
+	num = 0
+	nodeToPACE(decomp.Root, encoding, &num, &maxBag, w, ioutil.Discard)
+	num = 0
+	nodeToPACE(decomp.Root, encoding, &num, &maxBag, ioutil.Discard, w)
+}
+
+// toPACE exports the tree of a decomposition in the .td format of the PACE challenge. Renumbered vertices
+// are listed with their names in comments preceding the header.
+func toPACE(decomp Decomp) string {
+	var names, bags, tree bytes.Buffer
+
+	encoding, renumbered := paceEncoding(decomp.Graph)
+	num := 0
+	maxBag := 0
+	nodeToPACE(decomp.Root, encoding, &num, &maxBag, &bags, &tree)
+	if renumbered {
+		writePACENames(&names, encoding)
+	}
+
+	header := fmt.Sprintf("s td %d %d %d\n", num, maxBag, len(encoding))
+
+	return names.String() + header + bags.String() + tree.String()
+}
+
+// nodeToPACE writes the bags and tree edges of the subtree rooted at n, numbering the bags in preorder
+// starting from 1, and returns the id of n
+func nodeToPACE(n lib.Node, encoding map[int]int, num, maxBag *int, bags, tree io.Writer) int {
+	*num++
+	id := *num
+
+	if len(n.Bag) > *maxBag {
+		*maxBag = len(n.Bag)
+	}
+
+	io.WriteString(bags, "b "+strconv.Itoa(id))
+	for _, v := range n.Bag {
+		io.WriteString(bags, " "+strconv.Itoa(encoding[v]))
+	}
+	io.WriteString(bags, "\n")
+
+	for _, c := range n.Children {
+		child := nodeToPACE(c, encoding, num, maxBag, bags, tree)
+		fmt.Fprintf(tree, "%d %d\n", id, child)
+	}
+
+	return id
+}
+
+// writeDecisions stores the decisions recorded during the search in the file at path, to be replayed via -replay
+func writeDecisions(path string, decisions *logk.DecisionLog) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if err := decisions.WriteJSON(w); err != nil {
+		return err
+	}
+	return w.Flush()
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cem-okulmus/log-k-decomp/logk"
+)
+
+func TestGMLRoundTrip(t *testing.T) {
+	graph, err := readGraph("default", "testdata/grid3x4.hg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decomp := (&logk.LogKDecomp{Graph: graph, K: 2, BalFactor: 2}).FindDecomp()
+	if logk.IsEmptyDecomp(decomp) || len(decomp.Root.Children) == 0 {
+		t.Fatalf("want a decomp of more than one node, got %v", decomp)
+	}
+
+	// both writers number the nodes differently, yet each has to be read back as the same tree
+	writers := []struct {
+		name  string
+		write func(Decomp) string
+	}{
+		{"writeGML", func(d Decomp) string {
+			var buffer bytes.Buffer
+			writeGML(&buffer, d)
+			return buffer.String()
+		}},
+		{"Decomp.ToGML", Decomp.ToGML},
+	}
+
+	for _, w := range writers {
+		t.Run(w.name, func(t *testing.T) {
+			read, err := fromGML([]byte(w.write(decomp)), graph)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !logk.EqualNodes(read.Root, decomp.Root) {
+				t.Errorf("got the tree\n%v\nwant\n%v", read.Root, decomp.Root)
+			}
+			if !read.Correct(graph) {
+				t.Errorf("the decomp read back is incorrect: %v", read)
+			}
+		})
+	}
+}
+
+func TestNamesInOutputs(t *testing.T) {
+	// order_id and order_date get merged by Type Collapse, Salary and Bonus get removed by GYÖ
+	vertices := []string{"emp_id", "dept_id", "mgr_id", "amount", "order_id", "order_date"}
+	edges := []string{"Employee", "Dept", "Salary", "Bonus", "Orders"}
+
+	dir, err := ioutil.TempDir("", "logk-names")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, stream := range []bool{false, true} {
+		t.Run(fmt.Sprintf("streamout %v", stream), func(t *testing.T) {
+			files := map[string]string{}
+			args := []string{"-graph", "testdata/named.hg", "-logk", "-width", "2", "-g", "-t"}
+			for _, format := range []string{"json", "dot", "gml", "tdout"} {
+				files[format] = filepath.Join(dir, format)
+				args = append(args, "-"+format, files[format])
+			}
+			if stream {
+				args = append(args, "-streamout")
+			}
+
+			stdout, stderr, code := runMain(t, args...)
+			if code != 0 || !strings.Contains(stdout, "Correct:  true\n") {
+				t.Fatalf("got exit code %d:\n%s%s", code, stdout, stderr)
+			}
+
+			outputs := map[string]string{"stdout": stdout}
+			for format, path := range files {
+				data, err := ioutil.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				outputs[format] = string(data)
+			}
+			for format, output := range outputs {
+				names := vertices
+				if format != "tdout" { // the PACE format has no edges
+					names = append(append([]string{}, vertices...), edges...)
+				}
+				for _, name := range names {
+					if !strings.Contains(output, name) {
+						t.Errorf("%s: missing the name %s in:\n%s", format, name, output)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFromGMLMalformed(t *testing.T) {
+	graph, err := readGraph("default", "testdata/path.hg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile("testdata/path.gml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, input := range []string{"", "graph [", string(data[:len(data)/2]), "node [ id 1 ]"} {
+		if _, err := fromGML([]byte(input), graph); err == nil {
+			t.Errorf("got no error for the input %q", input)
+		}
+	}
+}
+
+func TestVerifyGML(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logk-gml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// path.gml is as written by BalancedGo, with e2 at the root
+	fixture, err := ioutil.ReadFile("testdata/path.gml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gmlFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	written := filepath.Join(dir, "written.gml")
+	if _, stderr, code := runMain(t, "-graph", "testdata/path.hg", "-logk", "-width", "1", "-gml", written); code != 0 {
+		t.Fatalf("got exit code %d writing the decomp:\n%s", code, stderr)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		wantCode int
+		want     string // expected on stdout, or on stderr for errors
+	}{
+		{"BalancedGo output", "testdata/path.gml", 0, "Correct:  true\n"},
+		{"-gml output", written, 0, "Correct:  true\n"},
+		// e3 moves below e1, s.t. the nodes containing c are no longer connected
+		{"incorrect", gmlFile("incorrect.gml", strings.Replace(string(fixture), "source 8\n    target 9",
+			"source 10\n    target 9", 1)), exitIncorrect, "Correct:  false\n"},
+		{"malformed", gmlFile("malformed.gml", string(fixture[:len(fixture)/2])), exitError,
+			"Could not parse the decomposition"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr, code := runMain(t, "-graph", "testdata/path.hg", "-verify", tt.path)
+
+			if code != tt.wantCode {
+				t.Fatalf("got exit code %d, want %d:\n%s%s", code, tt.wantCode, stdout, stderr)
+			}
+			output := stdout
+			if tt.wantCode == exitError {
+				output = stderr
+			}
+			if !strings.Contains(output, tt.want) || strings.Contains(stderr, "panic") {
+				t.Errorf("want %q without a panic, got:\n%s%s", tt.want, stdout, stderr)
+			}
+			if tt.wantCode != exitError && !strings.Contains(stdout, "Width:  1\n") {
+				t.Errorf("want width 1, got:\n%s", stdout)
+			}
+		})
+	}
+}
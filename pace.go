@@ -0,0 +1,113 @@
+package main
+
+// pace.go reads graphs in the PACE 2019 format line by line, s.t. large inputs never have to be held in
+// memory as a whole
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// readPACE reads a graph in the PACE 2019 format, i.e. a problem line "p htd <vertices> <edges>" followed
+// by one line "<edge> <v1> <v2> ..." per edge, with comment lines starting with "c". Just like for
+// lib.GetGraphPACE, edges are named E<n> and vertices V<n>. The names are kept in inputNames, as lib
+// only names the graphs it parses itself.
+func readPACE(r io.Reader) (Graph, error) {
+	numVertices, declaredEdges := -1, 0
+	var names []int            // the names of the edges, in the order they appear
+	var edges [][]int          // the vertices of each edge, as indices into vertices
+	var vertices []int         // the vertices, in the order they first appear
+	index := make(map[int]int) // the index of each vertex in vertices
+	seen := make(map[int]bool) // the edge names seen so far
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<26) // edges of huge arity make for long lines
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		fields := strings.Fields(text)
+		if len(fields) == 0 || fields[0] == "c" || strings.HasPrefix(fields[0], "//") {
+			continue
+		}
+		lineError := func(msg string) error {
+			return &parseError{Line: line, Text: strings.TrimRight(text, "\r"), Msg: msg}
+		}
+
+		if fields[0] == "p" {
+			if numVertices >= 0 {
+				return Graph{}, lineError("repeated problem line")
+			}
+			if len(fields) != 4 || fields[1] != "htd" {
+				return Graph{}, lineError("expected \"p htd <vertices> <edges>\"")
+			}
+			var errV, errE error
+			numVertices, errV = strconv.Atoi(fields[2])
+			declaredEdges, errE = strconv.Atoi(fields[3])
+			if errV != nil || errE != nil || numVertices < 0 || declaredEdges < 0 {
+				return Graph{}, lineError("invalid problem line")
+			}
+			continue
+		}
+
+		if numVertices < 0 {
+			return Graph{}, lineError("edge before the problem line")
+		}
+		name, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return Graph{}, lineError(fmt.Sprintf("invalid edge name %q", fields[0]))
+		}
+		if seen[name] {
+			return Graph{}, lineError(fmt.Sprintf("repeated edge %d", name))
+		}
+		seen[name] = true
+
+		edge := make([]int, 0, len(fields)-1)
+		for _, f := range fields[1:] {
+			v, err := strconv.Atoi(f)
+			if err != nil || v < 1 || v > numVertices {
+				return Graph{}, lineError(fmt.Sprintf("invalid vertex %q", f))
+			}
+			i, ok := index[v]
+			if !ok {
+				i = len(vertices)
+				index[v] = i
+				vertices = append(vertices, v)
+			}
+			edge = append(edge, i)
+		}
+		names = append(names, name)
+		edges = append(edges, edge)
+	}
+	if err := scanner.Err(); err != nil {
+		return Graph{}, err
+	}
+
+	if numVertices < 0 {
+		return Graph{}, errors.New("missing problem line")
+	}
+	if len(edges) != declaredEdges {
+		return Graph{}, fmt.Errorf("found %d edges, but the problem line declares %d", len(edges), declaredEdges)
+	}
+	if len(edges) == 0 {
+		inputNames = &paceNames{}
+		return Graph{}, nil
+	}
+
+	inputNames = &paceNames{edges: names, vertices: vertices}
+
+	// the edges are encoded as 1, 2, ..., followed by the vertices in the order they first appear
+	output := make([]Edge, len(edges))
+	for i, edge := range edges {
+		for j := range edge {
+			edge[j] += len(edges) + 1
+		}
+		output[i] = Edge{Name: i + 1, Vertices: edge}
+	}
+
+	return Graph{Edges: lib.NewEdges(output)}, nil
+}
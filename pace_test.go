@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// pacePath is a path in the PACE format, with edge names that differ from their positions, and V4 removed
+// from E9 by GYÖ
+const pacePath = `c a path
+p htd 5 4
+7 1 2
+3 2 3
+9 3 4 5
+4 5 1
+`
+
+func TestPACENames(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logk-pace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "path.pace")
+	if err := ioutil.WriteFile(input, []byte(pacePath), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("graph", func(t *testing.T) {
+		graph, err := readGraph("pace", input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// lib renames whatever it parses, which must not touch the names of a graph read by readPACE
+		lib.GetGraph("R(a,b), S(b,c).")
+		if got, want := printGraph(graph), "{E7, E3, E9, E4}"; got != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+	})
+
+	t.Run("outputs", func(t *testing.T) {
+		files := map[string]string{}
+		args := []string{"-graph", input, "-format", "pace", "-logk", "-width", "2", "-g", "-t"}
+		for _, format := range []string{"json", "dot", "gml"} {
+			files[format] = filepath.Join(dir, format)
+			args = append(args, "-"+format, files[format])
+		}
+
+		stdout, stderr, code := runMain(t, args...)
+		if code != 0 || !strings.Contains(stdout, "Correct:  true\n") {
+			t.Fatalf("got exit code %d:\n%s%s", code, stdout, stderr)
+		}
+		if !strings.Contains(stdout, "(V4 ∈ E9)") {
+			t.Errorf("stdout: missing the reduction (V4 ∈ E9) in:\n%s", stdout)
+		}
+
+		outputs := map[string]string{"stdout": stdout}
+		for format, path := range files {
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			outputs[format] = string(data)
+		}
+		for format, output := range outputs {
+			for _, name := range []string{"E7", "E3", "E9", "E4", "V1", "V2", "V3", "V5"} {
+				if !strings.Contains(output, name) {
+					t.Errorf("%s: missing the name %s in:\n%s", format, name, output)
+				}
+			}
+		}
+	})
+}
+
+// BenchmarkReadPACE compares reading a large PACE file line by line against reading it whole and parsing
+// it with lib, as was done before readPACE
+func BenchmarkReadPACE(b *testing.B) {
+	dir, err := ioutil.TempDir("", "logk-pace")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	const numVertices, numEdges = 20000, 50000
+	input := filepath.Join(dir, "large.pace")
+	file, err := os.Create(input)
+	if err != nil {
+		b.Fatal(err)
+	}
+	w := bufio.NewWriter(file)
+	fmt.Fprintf(w, "p htd %d %d\n", numVertices, numEdges)
+	r := rand.New(rand.NewSource(1))
+	for e := 1; e <= numEdges; e++ {
+		fmt.Fprint(w, e)
+		first, arity := r.Intn(numVertices-5), 2+r.Intn(4)
+		for v := first; v < first+arity; v++ {
+			fmt.Fprint(w, " ", v+1)
+		}
+		fmt.Fprintln(w)
+	}
+	if err := w.Flush(); err != nil {
+		b.Fatal(err)
+	}
+	if err := file.Close(); err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("streaming", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := readGraph("pace", input); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("read all", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			dat, err := readInput(input)
+			if err != nil {
+				b.Fatal(err)
+			}
+			lib.GetGraphPACE(string(dat))
+		}
+	})
+}
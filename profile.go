@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// widthProfile describes how the width is distributed over the nodes of a decomposition
+type widthProfile struct {
+	covers   map[int]int // number of nodes for each cover size
+	bags     map[int]int // number of nodes for each bag size
+	depthMax []int       // largest cover at each depth, starting with the root
+}
+
+// profileWidth walks the tree of the decomp level by level, collecting the sizes of the covers and bags
+func profileWidth(decomp Decomp) widthProfile {
+	output := widthProfile{covers: make(map[int]int), bags: make(map[int]int)}
+
+	current := []lib.Node{decomp.Root}
+	for len(current) > 0 {
+		var children []lib.Node
+		maxCover := 0
+		for _, n := range current {
+			output.covers[n.Cover.Len()]++
+			output.bags[len(n.Bag)]++
+			if n.Cover.Len() > maxCover {
+				maxCover = n.Cover.Len()
+			}
+			children = append(children, n.Children...)
+		}
+		output.depthMax = append(output.depthMax, maxCover)
+		current = children
+	}
+
+	return output
+}
+
+// histogram prints the counts of a size distribution, ordered by size
+func histogram(buffer *bytes.Buffer, counts map[int]int) {
+	var sizes []int
+	for size := range counts {
+		sizes = append(sizes, size)
+	}
+	sort.Ints(sizes)
+
+	for _, size := range sizes {
+		fmt.Fprintf(buffer, "  %d: %d\n", size, counts[size])
+	}
+}
+
+func (p widthProfile) String() string {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("Nodes per cover size:\n")
+	histogram(&buffer, p.covers)
+	buffer.WriteString("Nodes per bag size:\n")
+	histogram(&buffer, p.bags)
+	buffer.WriteString("Largest cover per depth:\n")
+	for depth, width := range p.depthMax {
+		fmt.Fprintf(&buffer, "  %d: %d\n", depth, width)
+	}
+
+	return buffer.String()
+}
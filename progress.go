@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/cem-okulmus/log-k-decomp/logk"
+)
+
+// reportProgress prints the width currently tried and the elapsed time to stderr every interval, until
+// done is closed. If stats is not nil, the number of recursive calls and the cache size are added.
+func reportProgress(width *int64, stats func() logk.Stats, interval time.Duration, done <-chan struct{}) {
+	start := time.Now()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			line := fmt.Sprintf("Progress: K = %d, elapsed %.1f s", atomic.LoadInt64(width),
+				time.Now().Sub(start).Seconds())
+			if stats != nil {
+				s := stats()
+				line = line + fmt.Sprintf(", recursive calls: %d, cache size: %d", s.Calls, s.CacheSize)
+			}
+			fmt.Fprintln(os.Stderr, line)
+		}
+	}
+}
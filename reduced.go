@@ -0,0 +1,150 @@
+package main
+
+// reduced.go writes the graph left by the reductions in one of the input formats, s.t. it can be
+// decomposed by other tools, along with the reductions needed to restore a decomp of the input
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// graphWriters maps the input formats to the writers producing them
+var graphWriters = map[string]func(w io.Writer, g Graph, comments []string){
+	"default": writeDefault,
+	"pace":    writePACEGraph,
+	"dimacs":  writeDIMACS,
+}
+
+// reductionComments describes the reductions applied to the input, in the order they have to be undone on
+// a decomp of the reduced graph, i.e. the GYÖ reductions first, followed by the Type Collapse
+func reductionComments(ops []lib.GYÖReduct, removalMap map[int][]int) []string {
+	var output []string
+	if len(ops) > 0 || len(removalMap) > 0 {
+		output = append(output, "undo the following reductions, in this order, on a decomp of this graph")
+	}
+	for _, op := range ops {
+		output = append(output, "GYÖ "+printGYÖ(op))
+	}
+
+	var representatives []int
+	for v := range removalMap {
+		representatives = append(representatives, v)
+	}
+	sort.Ints(representatives)
+	for _, v := range representatives {
+		output = append(output, fmt.Sprintf("Type Collapse %s into %s", strings.Join(vertexNames(removalMap[v]), " "), vertexName(v)))
+	}
+
+	return output
+}
+
+// dumpReduced writes the reduced graph into the file at path, in the named input format
+func dumpReduced(path string, format string, g Graph, ops []lib.GYÖReduct, removalMap map[int][]int) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	graphWriters[format](w, g, reductionComments(ops, removalMap))
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// writeDefault writes a graph in the HyperBench format, with comments starting with "%"
+func writeDefault(w io.Writer, g Graph, comments []string) {
+	for _, c := range comments {
+		fmt.Fprintln(w, "%", c)
+	}
+
+	for i, e := range g.Edges.Slice() {
+		fmt.Fprintf(w, "%s(%s)", edgeName(e), strings.Join(vertexNames(e.Vertices), ","))
+		if i < g.Edges.Len()-1 {
+			fmt.Fprintln(w, ",")
+		} else {
+			fmt.Fprintln(w, ".")
+		}
+	}
+}
+
+// numberedEdges numbers the edges of a graph for the PACE format, keeping the numbers of those read with
+// -format pace, and numbering them in order otherwise
+func numberedEdges(g Graph) []int {
+	output := make([]int, g.Edges.Len())
+	for i, e := range g.Edges.Slice() {
+		name := edgeName(e)
+		n, err := strconv.Atoi(strings.TrimPrefix(name, "E"))
+		if err != nil || !strings.HasPrefix(name, "E") {
+			for j := range output {
+				output[j] = j + 1
+			}
+			return output
+		}
+		output[i] = n
+	}
+	return output
+}
+
+// maxNumber returns the largest number of any vertex, which the problem lines declare as their number
+func maxNumber(encoding map[int]int) int {
+	output := 0
+	for _, n := range encoding {
+		if n > output {
+			output = n
+		}
+	}
+	return output
+}
+
+// writePACEGraph writes a graph in the PACE 2019 format. Vertices that got renumbered are listed with
+// their names in comments, just like for the decomps written with -tdout.
+func writePACEGraph(w io.Writer, g Graph, comments []string) {
+	for _, c := range comments {
+		fmt.Fprintln(w, "c", c)
+	}
+	encoding, renumbered := paceEncoding(g)
+	if renumbered {
+		writePACENames(w, encoding)
+	}
+
+	fmt.Fprintf(w, "p htd %d %d\n", maxNumber(encoding), g.Edges.Len())
+	names := numberedEdges(g)
+	for i, e := range g.Edges.Slice() {
+		fmt.Fprint(w, names[i])
+		for _, v := range e.Vertices {
+			fmt.Fprint(w, " ", encoding[v])
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// writeDIMACS writes a graph in the DIMACS-like edge format, numbering the vertices just like for the PACE
+// format. The names of the edges are lost, as the format numbers them in order.
+func writeDIMACS(w io.Writer, g Graph, comments []string) {
+	for _, c := range comments {
+		fmt.Fprintln(w, "c", c)
+	}
+	encoding, renumbered := paceEncoding(g)
+	if renumbered {
+		writePACENames(w, encoding)
+	}
+
+	fmt.Fprintf(w, "p edge %d %d\n", maxNumber(encoding), g.Edges.Len())
+	for _, e := range g.Edges.Slice() {
+		fmt.Fprint(w, "e")
+		for _, v := range e.Vertices {
+			fmt.Fprint(w, " ", encoding[v])
+		}
+		fmt.Fprintln(w)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// canonicalEdges describes each edge of a graph by the sorted names of its vertices, renamed via rename,
+// prefixed by the name of the edge if withNames is set
+func canonicalEdges(g Graph, withNames bool, rename func(string) string) []string {
+	var output []string
+	for _, e := range g.Edges.Slice() {
+		var names []string
+		for _, v := range e.Vertices {
+			names = append(names, rename(vertexName(v)))
+		}
+		sort.Strings(names)
+		edge := "(" + strings.Join(names, ",") + ")"
+		if withNames {
+			edge = e.String() + edge
+		}
+		output = append(output, edge)
+	}
+	sort.Strings(output)
+	return output
+}
+
+func TestDumpedGraphRoundTrip(t *testing.T) {
+	for format := range graphWriters {
+		t.Run(format, func(t *testing.T) {
+			// lib renames the vertices with every graph it parses, so the input is read anew each time
+			graph, err := readGraph("default", "testdata/named.hg")
+			if err != nil {
+				t.Fatal(err)
+			}
+			withNames := format == "default" // the other formats number the edges
+			want := canonicalEdges(graph, withNames, func(name string) string { return name })
+
+			var buffer bytes.Buffer
+			graphWriters[format](&buffer, graph, []string{"GYÖ (Salary ⊆ Bonus)"})
+			dump := buffer.String()
+			if !strings.Contains(dump, "GYÖ (Salary ⊆ Bonus)\n") {
+				t.Errorf("missing the reduction in the comments:\n%s", dump)
+			}
+
+			// renumbered vertices are listed by the comments "c <number> <name>"
+			names := make(map[string]string)
+			for _, line := range strings.Split(dump, "\n") {
+				if fields := strings.Fields(line); len(fields) == 3 && fields[0] == "c" {
+					names["V"+fields[1]] = fields[2]
+				}
+			}
+
+			dumped, err := parseGraph(format, dump)
+			if err != nil {
+				t.Fatalf("%v, parsing:\n%s", err, dump)
+			}
+			got := canonicalEdges(dumped, withNames, func(name string) string {
+				if original, ok := names[name]; ok {
+					return original
+				}
+				return name
+			})
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got the edges %v, want %v, parsing:\n%s", got, want, dump)
+			}
+		})
+	}
+}
+
+func TestDumpReduced(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logk-reduced")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "reduced.hg")
+
+	_, stderr, code := runMain(t, "-graph", "testdata/named.hg", "-logk", "-width", "2", "-t", "-g",
+		"-dumpreduced", path)
+	if code != 0 {
+		t.Fatalf("got exit code %d:\n%s", code, stderr)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// GYÖ leaves the triangle, after Type Collapse merged order_date into order_id
+	for _, line := range []string{"% GYÖ (Salary ⊆ Bonus)", "% Type Collapse order_date into order_id"} {
+		if !strings.Contains(string(data), line+"\n") {
+			t.Errorf("missing %q in the dump:\n%s", line, data)
+		}
+	}
+	reduced, err := parseGraph("default", string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Dept(dept_id,mgr_id)", "Employee(dept_id,emp_id)", "Manager(emp_id,mgr_id)"}
+	if got := canonicalEdges(reduced, true, func(name string) string { return name }); !reflect.DeepEqual(got, want) {
+		t.Errorf("got the reduced graph %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cem-okulmus/log-k-decomp/logk"
+)
+
+// repeatRun is the outcome of one of the runs of -repeat
+type repeatRun struct {
+	time  time.Duration // the time taken by the search, including -exact and -refine
+	width int           // the width of the decomp found, or -1 if none was found
+}
+
+func newRepeatRun(d time.Duration, decomp Decomp) repeatRun {
+	output := repeatRun{time: d, width: -1}
+	if !logk.IsEmptyDecomp(decomp) {
+		output.width = decomp.CheckWidth()
+	}
+	return output
+}
+
+// summarizeRuns prints the spread of the times taken by the runs, and the widths they found. As the
+// parallel search picks whichever balanced separator it finds first, the width found may differ between
+// runs of the same search, e.g. with -refine.
+func summarizeRuns(runs []repeatRun) string {
+	var buffer bytes.Buffer
+
+	msecs := make([]float64, len(runs))
+	sum := 0.0
+	for i, r := range runs {
+		msecs[i] = r.time.Seconds() * float64(time.Second/time.Millisecond)
+		sum += msecs[i]
+	}
+	mean := sum / float64(len(runs))
+	variance := 0.0
+	for _, msec := range msecs {
+		variance += (msec - mean) * (msec - mean)
+	}
+	if len(runs) > 1 {
+		variance /= float64(len(runs) - 1) // the sample variance, as the runs are a sample of all possible ones
+	}
+
+	sort.Float64s(msecs)
+	median := msecs[len(msecs)/2]
+	if len(msecs)%2 == 0 {
+		median = (msecs[len(msecs)/2-1] + msecs[len(msecs)/2]) / 2
+	}
+
+	fmt.Fprintf(&buffer, "Runs: %d\n", len(runs))
+	fmt.Fprintf(&buffer, "Time min: %.5f ms, median: %.5f ms, mean: %.5f ms, stddev: %.5f ms\n", msecs[0], median,
+		mean, math.Sqrt(variance))
+
+	widths := make(map[int]int)
+	for _, r := range runs {
+		widths[r.width]++
+	}
+	if len(widths) == 1 {
+		fmt.Fprintln(&buffer, "Width stable: true")
+		return buffer.String()
+	}
+
+	fmt.Fprintln(&buffer, "Width stable: false, runs per width (-1 for none found):")
+	histogram(&buffer, widths)
+	return buffer.String()
+}
@@ -0,0 +1,560 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+	"github.com/cem-okulmus/log-k-decomp/logk"
+)
+
+// searchResult is the outcome of a run of the search
+type searchResult struct {
+	decomp     Decomp
+	width      int  // the width searched for, or the one found by -exact and -approx
+	firstWidth int  // the width of the first decomp found, if -refine went on to look for smaller ones
+	optimal    bool // whether -exact or -approx ruled out all widths below the one found
+}
+
+// searcher searches for a decomp of a prepared graph, via -exact, -approx or a single search at the given
+// width, each followed by -refine if asked for. The searches of each mode return the exit code, which is
+// non-zero once the search got stopped before finding a decomp, or failed with an error.
+type searcher struct {
+	opts      *options
+	ctx       context.Context
+	input     *preparedGraph
+	solver    logk.Algorithm
+	graphPath string
+	connected []Graph // the connected components of the graph, decomposed one at a time
+
+	start         time.Time // the start of the current run
+	triedWidth    int64     // the width currently tried, read concurrently by the progress reports
+	memoryReached int32     // set once the search got stopped due to the memory limit
+
+	// a failed search is inconclusive once it abandoned any recursive call due to the sub-timeout, or
+	// the bound on the separators tried per call, be it the search of the whole graph, or of a part of it
+	incomplete   bool
+	incompleteMu sync.Mutex // the searches of -exactparallel run concurrently
+}
+
+func (s *searcher) markIncomplete() {
+	s.incompleteMu.Lock()
+	s.incomplete = true
+	s.incompleteMu.Unlock()
+}
+
+func (s *searcher) noteIncomplete(solver logk.Algorithm) {
+	if logK, ok := solver.(*logk.LogKDecomp); ok && logK.Incomplete() {
+		s.markIncomplete()
+	}
+}
+
+// decompose runs a single search of the given solver, in whichever way the flags ask for, and may run
+// concurrently with other searches, so errors are returned rather than acted upon. Searches that are
+// merely inconclusive return no error, as they are noted as incomplete here, or by checkCancelled.
+func (s *searcher) decompose(solver logk.Algorithm) (decomp Decomp, err error) {
+	graph := s.input.graph
+	switch {
+	case s.opts.hinge && s.opts.hingeParallel > 0:
+		K := solver.(*logk.LogKDecomp).K // the exact searches set the width before each call
+		decomp, err = decompHingeParallel(s.ctx, s.input.hinget, graph, s.opts.hingeParallel,
+			func(ctx context.Context) logk.Algorithm {
+				logK := newLogK(s.opts, s.input, K)
+				logK.SetContext(ctx)
+				return logK
+			})
+	case s.opts.hinge: // the exact searches set the width of the solver before each call
+		decomp, err = decompHinge(s.input.hinget, solver, graph)
+	case len(s.connected) > 1:
+		decomp, err = decompJoined(solver, s.connected, graph)
+	default:
+		decomp, err = findDecompGraph(solver, graph)
+	}
+	if errors.Is(err, logk.ErrIncomplete) {
+		s.markIncomplete()
+	}
+	if inconclusive(err) {
+		return decomp, nil
+	}
+	return decomp, err
+}
+
+// checkCancelled checks if the search ran out of time, or got interrupted before finding a decomp
+func (s *searcher) checkCancelled(decomp Decomp, K int) int {
+	if !logk.IsEmptyDecomp(decomp) {
+		return 0
+	}
+	name := s.solver.Name()
+	if atomic.LoadInt32(&s.memoryReached) != 0 {
+		if s.opts.quiet {
+			fmt.Println(name, s.graphPath, "MEMLIMIT")
+		} else {
+			fmt.Printf("Used algorithm: %s\nmemory limit reached at K=%d\n", name, K)
+		}
+		return exitMemory
+	}
+	if s.ctx.Err() == context.DeadlineExceeded {
+		if s.opts.quiet {
+			fmt.Println(name, s.graphPath, "TIMEOUT")
+		} else {
+			fmt.Printf("Used algorithm: %s\ntimed out at K=%d\n", name, K)
+		}
+		return exitTimeout
+	}
+	if s.ctx.Err() == context.Canceled {
+		if s.opts.quiet {
+			fmt.Println(name, s.graphPath, "INTERRUPTED")
+		} else {
+			fmt.Printf("Used algorithm: %s\ninterrupted at K=%d\n", name, K)
+		}
+		return exitInterrupt
+	}
+	return 0
+}
+
+// trivialWidth is the width of the decomp covering the whole graph by its root, i.e. |E|, or the total
+// weight of the edges
+func (s *searcher) trivialWidth() int {
+	graph := s.input.graph
+	if s.input.weights != nil {
+		return int(math.Ceil(logk.WeightedWidth(Decomp{Root: lib.Node{Cover: graph.Edges}}, s.input.weights)))
+	}
+	return graph.Edges.Len()
+}
+
+// searchExact searches for the smallest width for which a decomp exists, starting from a lower bound
+func (s *searcher) searchExact(first bool) (searchResult, int) {
+	lowerBound := computeLowerBound(s.input.graph)
+	if !s.opts.quiet && first {
+		fmt.Println("Lower bound: ", lowerBound)
+	}
+	upperBound := s.trivialWidth() // any graph has a decomp of this width
+
+	if s.opts.exactParallel > 0 {
+		best, optimal, err := searchWidthsParallel(s.ctx, lowerBound, upperBound, s.opts.exactParallel,
+			&s.triedWidth, func(ctx context.Context, K int) (Decomp, logk.Algorithm, error) {
+				logK := newLogK(s.opts, s.input, K)
+				logK.SetContext(ctx)
+				decomp, err := s.decompose(logK)
+				s.noteIncomplete(logK)
+				return decomp, logK, err
+			})
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return searchResult{}, exitError
+		}
+		result := searchResult{decomp: best.Decomp, width: best.K, optimal: optimal}
+		if code := s.checkCancelled(result.decomp, result.width); code != 0 {
+			return result, code
+		}
+		if best.Solver != nil {
+			s.solver = best.Solver // to report on the search that found the decomp
+		}
+		return result, 0
+	}
+
+	code := 0
+	decomp, width := searchWidths(lowerBound, upperBound, &s.triedWidth, func(K int) (Decomp, bool) {
+		if resetter, ok := s.solver.(interface{ ResetStats() }); ok {
+			resetter.ResetStats() // only report the statistics of the final width
+		}
+		s.solver.SetWidth(K)
+		decomp, err := s.decompose(s.solver)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = exitError
+			return decomp, true
+		}
+		s.noteIncomplete(s.solver)
+		code = s.checkCancelled(decomp, K)
+		return decomp, code != 0
+	})
+	return searchResult{decomp: decomp, width: width, optimal: true}, code
+}
+
+// searchApprox is the anytime search: it starts from an upper bound, and tries ever smaller widths below
+// the one of each decomp found, until a width fails, or the timeout fires, keeping the last decomp
+func (s *searcher) searchApprox(first bool) (searchResult, int) {
+	lowerBound := computeLowerBound(s.input.graph)
+	upperBound := computeUpperBound(s.input.graph, s.opts.ghd).width
+	if s.input.weights != nil {
+		upperBound = s.trivialWidth()
+	}
+	if !s.opts.quiet && first {
+		fmt.Println("Lower bound: ", lowerBound)
+		fmt.Println("Upper bound: ", upperBound)
+	}
+
+	result := searchResult{width: upperBound}
+	for K := upperBound; K >= lowerBound && K > 0; K-- {
+		if resetter, ok := s.solver.(interface{ ResetStats() }); ok {
+			resetter.ResetStats() // only report the statistics of the last width
+		}
+		atomic.StoreInt64(&s.triedWidth, int64(K))
+		s.solver.SetWidth(K)
+		found, err := s.decompose(s.solver)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return result, exitError
+		}
+		s.noteIncomplete(s.solver)
+		if s.ctx.Err() != nil {
+			break
+		}
+		if logk.IsEmptyDecomp(found) {
+			result.optimal = !logk.IsEmptyDecomp(result.decomp) && !s.incomplete
+			break
+		}
+		result.decomp, result.width = found, found.CheckWidth() // may well be smaller than K
+		K = result.width
+	}
+	if !logk.IsEmptyDecomp(result.decomp) && result.width <= lowerBound {
+		result.optimal = true
+	}
+	return result, s.checkCancelled(result.decomp, result.width)
+}
+
+// searchSingle searches for a decomp of the given width
+func (s *searcher) searchSingle(width int) (searchResult, int) {
+	decomp, err := s.decompose(s.solver)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return searchResult{width: width}, exitError
+	}
+	s.noteIncomplete(s.solver)
+	return searchResult{decomp: decomp, width: width}, s.checkCancelled(decomp, width)
+}
+
+// refine tries ever smaller widths below the one of the decomp found, keeping the last decomp until a width
+// fails, or the time runs out
+func (s *searcher) refine(result searchResult) (searchResult, int) {
+	if logk.IsEmptyDecomp(result.decomp) {
+		return result, 0
+	}
+	result.firstWidth = result.decomp.CheckWidth()
+	for K := result.firstWidth - 1; K > 0; K-- {
+		atomic.StoreInt64(&s.triedWidth, int64(K))
+		s.solver.SetWidth(K)
+		refined, err := s.decompose(s.solver)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return result, exitError
+		}
+		s.noteIncomplete(s.solver)
+		if s.ctx.Err() != nil || logk.IsEmptyDecomp(refined) {
+			break
+		}
+		result.decomp, result.width = refined, K
+		K = refined.CheckWidth() // may well be smaller than K
+	}
+	return result, 0
+}
+
+// search runs the search in whichever mode the flags ask for. first tells if this is the first of the
+// runs of -repeat, which print the bounds.
+func (s *searcher) search(width int, first bool) (searchResult, int) {
+	var result searchResult
+	var code int
+	switch {
+	case s.opts.exact:
+		result, code = s.searchExact(first)
+	case s.opts.approx > 0:
+		result, code = s.searchApprox(first)
+	default:
+		result, code = s.searchSingle(width)
+	}
+	if code != 0 || !s.opts.refine {
+		return result, code
+	}
+	return s.refine(result)
+}
+
+// searchRuns runs the search once, or as many times as -repeat asks for, each starting from scratch. Only
+// the last run gets reported on, apart from the summary of all of them.
+func (s *searcher) searchRuns(width int) (searchResult, []repeatRun, int) {
+	var result searchResult
+	var runs []repeatRun
+	for run := 0; run < s.opts.repeat || run == 0; run++ {
+		if run > 0 {
+			if resetter, ok := s.solver.(interface{ ResetCaches() }); ok {
+				resetter.ResetCaches()
+			}
+			if resetter, ok := s.solver.(interface{ ResetStats() }); ok {
+				resetter.ResetStats()
+			}
+			s.incomplete = false
+			atomic.StoreInt64(&s.triedWidth, int64(width))
+			s.solver.SetWidth(width)
+		}
+		s.start = time.Now()
+
+		var code int
+		result, code = s.search(width, run == 0)
+		if code != 0 {
+			return result, runs, code
+		}
+		runs = append(runs, newRepeatRun(time.Now().Sub(s.start), result.decomp))
+	}
+	return result, runs, 0
+}
+
+// searchGraph searches for a decomp of the prepared graph with the given solver, in whichever mode the flags
+// ask for, and outputs it. It returns the exit code.
+func searchGraph(ctx context.Context, opts *options, input *preparedGraph, solver logk.Algorithm, graphPath string) int {
+	if opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(opts.timeout)*time.Second)
+		defer cancel()
+	}
+	if opts.approx > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(opts.approx)*time.Second)
+		defer cancel()
+	}
+
+	s := &searcher{opts: opts, input: input, solver: solver, graphPath: graphPath, triedWidth: int64(opts.width)}
+	if opts.maxMem > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		done := make(chan struct{})
+		defer close(done)
+		go watchMemory(uint64(opts.maxMem)<<20, memoryInterval, &s.memoryReached, cancel, done)
+	}
+	s.ctx = ctx
+	if ctxSolver, ok := solver.(contextAlgorithm); ok {
+		ctxSolver.SetContext(ctx)
+	}
+
+	if opts.progress {
+		var stats func() logk.Stats
+		if logK, ok := solver.(*logk.LogKDecomp); ok {
+			stats = logK.Stats
+		}
+		done := make(chan struct{})
+		defer close(done)
+		go reportProgress(&s.triedWidth, stats, progressInterval, done)
+	}
+
+	// disconnected graphs are decomposed one component at a time
+	s.connected = getConnectedComponents(input.graph)
+	if len(s.connected) > 1 && !opts.quiet {
+		fmt.Println("Number of components: ", len(s.connected))
+	}
+
+	// report each smaller width found, by the exact and approximating searches, -refine, or a single
+	// search at the given width; components and hinges are searched one at a time, so the widths found
+	// there are only those of parts of the graph
+	if logK, ok := solver.(*logk.LogKDecomp); ok && !opts.quiet && len(s.connected) == 1 && !opts.hinge {
+		logK.OnImprovement(func(width int, d lib.Decomp) {
+			msec := time.Now().Sub(s.start).Seconds() * float64(time.Second/time.Millisecond)
+			fmt.Fprintf(diagOut, "Found width %d at %.5f ms\n", width, msec)
+		})
+	}
+
+	result, runs, code := s.searchRuns(opts.width)
+	if code != 0 {
+		return code
+	}
+	if atomic.LoadInt32(&s.memoryReached) != 0 {
+		fmt.Fprintln(os.Stderr, "Memory limit reached, reporting the best decomp found so far")
+	}
+
+	d := time.Now().Sub(s.start)
+	msec := d.Seconds() * float64(time.Second/time.Millisecond)
+	times := append(input.times, labelTime{time: msec, label: "Decomposition"})
+
+	if input.decisions != nil {
+		if err := writeDecisions(opts.replayLog, input.decisions); err != nil {
+			fmt.Fprintln(os.Stderr, "Could not write the replay log:", err)
+		}
+	}
+
+	return s.output(result, runs, times)
+}
+
+// output undoes the reductions on the decomp found, checks it and prints it along with the summary of the
+// search, and writes it to the output files. It returns the exit code.
+func (s *searcher) output(result searchResult, runs []repeatRun, times []labelTime) int {
+	opts, input := s.opts, s.input
+	decomp, width := result.decomp, result.width
+	originalGraph := input.original
+
+	if !logk.IsEmptyDecomp(decomp) || (len(input.ops) > 0 && input.graph.Edges.Len() == 0) {
+		var err error
+		decomp.Root, err = logk.RestoreGYÖ(decomp.Root, input.ops)
+		if err == nil {
+			decomp.Root, err = logk.RestoreTypes(decomp.Root, input.removalMap)
+		}
+		if err == nil && opts.dedup {
+			decomp.Root = restoreDeduped(decomp.Root, originalGraph)
+		}
+		if err != nil {
+			var restoreErr *logk.RestoreError
+			if errors.As(err, &restoreErr) {
+				fmt.Fprintln(os.Stderr, "Partial decomp:", restoreErr.Partial)
+			}
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+	}
+
+	if !logk.IsEmptyDecomp(decomp) {
+		decomp.Graph = originalGraph
+	}
+	if len(originalGraph.Special) > 0 && !logk.IsEmptyDecomp(decomp) {
+		// the special edges end up as leaves, which lib can't check, so only the rest is checked
+		var leaves bool
+		decomp, leaves = removeSpecial(decomp)
+		if !opts.quiet {
+			fmt.Fprintln(resultOut, "Special edges found as leaves: ", leaves)
+		}
+		if !leaves {
+			decomp = Decomp{}
+		}
+		originalGraph = Graph{Edges: originalGraph.Edges}
+	}
+	if !logk.IsEmptyDecomp(decomp) {
+		decomp.RestoreSubedges() // s.t. every output, including -showroot and -minimize, uses the edges of the input
+	}
+	if opts.minimize && !logk.IsEmptyDecomp(decomp) {
+		var removed int
+		decomp.Root, removed = minimizeCovers(decomp.Root)
+		if !opts.quiet {
+			fmt.Fprintln(diagOut, "Edges removed from covers by minimization: ", removed)
+		}
+	}
+	outputs := []decompOutput{
+		{path: opts.gml, format: toGML, stream: writeGML},
+		{path: opts.jsonOut, format: toJSON},
+		{path: opts.dot, format: toDOT, stream: writeDOT},
+		{path: opts.tdOut, format: toPACE, stream: writePACE},
+	}
+	var correct bool
+	skip := opts.skipCheck && !logk.IsEmptyDecomp(decomp) // there is nothing to check otherwise
+	if opts.quiet {
+		correct = outputQuiet(s.solver.Name(), s.graphPath, decomp, originalGraph, outputs, skip)
+	} else {
+		correct = outputStanza(s.solver.Name(), input.heuristicName, decomp, times, originalGraph, outputs, width, skip)
+		if logk.IsEmptyDecomp(decomp) && s.incomplete {
+			fmt.Fprintf(resultOut, "Outcome: incomplete, no decomp of width %d found, but one may exist\n", width)
+		} else if logk.IsEmptyDecomp(decomp) {
+			fmt.Fprintf(resultOut, "Outcome: infeasible, no decomp of width %d exists\n", width)
+		}
+	}
+
+	if opts.csvOut != "" {
+		found := -1
+		if !logk.IsEmptyDecomp(decomp) {
+			found = decomp.CheckWidth()
+		}
+		err := appendCSV(opts.csvOut, s.graphPath, s.solver.Name(), width, found, correct, times)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Could not write the CSV summary:", err)
+		}
+	}
+	if s.incomplete {
+		warnOut := resultOut
+		if opts.quiet {
+			warnOut = os.Stderr
+		}
+		fmt.Fprintln(warnOut, "Warning: recursive calls were abandoned due to -subtimeout or -maxcandidates, or subtrees failed to attach, so the result may be incomplete:",
+			"failing to find a decomp doesn't prove that none exists")
+	}
+	if !logk.IsEmptyDecomp(decomp) {
+		if found, exceeds := exceedsWidth(decomp, width, input.weights); exceeds {
+			fmt.Fprintf(os.Stderr, "Warning: the decomp has width %g, exceeding the requested width %d. This is a bug, please report it\n",
+				found, width)
+		}
+	}
+	if opts.showRoot {
+		rootOut := diagOut
+		if opts.quiet {
+			rootOut = os.Stderr
+		}
+		printRoot(rootOut, decomp, s.solver)
+	}
+	if opts.quiet {
+		return 0
+	}
+
+	if !opts.exact && opts.approx == 0 && width >= originalGraph.Edges.Len() {
+		fmt.Fprintf(diagOut, "Warning: the width %d is not smaller than the number of edges (%d), so the graph decomposes trivially\n",
+			width, originalGraph.Edges.Len())
+	}
+
+	balancednessLimit := (input.graph.Len() * (opts.balanceFactor - 1)) / opts.balanceFactor
+	fmt.Fprintf(diagOut, "Balancedness limit of the root call: %d (balance factor %d)\n", balancednessLimit, opts.balanceFactor)
+
+	if opts.ghd && opts.logK {
+		fmt.Fprintln(resultOut, "Note: this is a GHD, so it may violate the special condition of hypertree decompositions")
+	}
+
+	if opts.exact && !logk.IsEmptyDecomp(decomp) {
+		if result.optimal {
+			fmt.Fprintln(resultOut, "Exact width: ", width)
+		} else {
+			fmt.Fprintln(resultOut, "Width found: ", width, "(smaller widths not ruled out before the search stopped)")
+		}
+	}
+
+	if opts.approx > 0 && !logk.IsEmptyDecomp(decomp) {
+		if result.optimal {
+			fmt.Fprintln(resultOut, "Approximated width: ", width, "(proven optimal)")
+		} else {
+			fmt.Fprintln(resultOut, "Approximated width: ", width, "(best found before the timeout)")
+		}
+	}
+
+	if result.firstWidth > 0 {
+		fmt.Fprintln(resultOut, "First width found: ", result.firstWidth)
+		fmt.Fprintln(resultOut, "Refined width: ", decomp.CheckWidth())
+	}
+
+	if input.weights != nil && !logk.IsEmptyDecomp(decomp) {
+		fmt.Fprintf(resultOut, "Weighted width: %g\n", logk.WeightedWidth(decomp, input.weights))
+	}
+
+	if opts.fhtw && !logk.IsEmptyDecomp(decomp) {
+		fmt.Fprintf(resultOut, "Fractional width: %.5f\n", fractionalWidth(decomp, originalGraph))
+	}
+
+	if logK, ok := s.solver.(*logk.LogKDecomp); ok {
+		if logK.MostBalanced > 1 {
+			fmt.Fprintf(diagOut, "Strategy: lookahead over %d children, choosing the one with the smallest largest component\n",
+				logK.MostBalanced)
+		} else {
+			fmt.Fprintln(diagOut, "Strategy: first balanced child")
+		}
+		fmt.Fprintf(diagOut, "Recursion depth: %d (log2 of the number of edges: %.2f)\n", logK.MaxDepth(),
+			math.Log2(float64(input.graph.Edges.Len())))
+	}
+
+	if logK, ok := s.solver.(*logk.LogKDecomp); ok && opts.stats {
+		fmt.Fprintln(diagOut, "\nStatistics:")
+		fmt.Fprintln(diagOut, logK.Stats())
+	}
+
+	if logKHyb, ok := s.solver.(*logk.LogKHybrid); ok && opts.stats {
+		fmt.Fprintln(diagOut, "\nPredicate decisions:")
+		fmt.Fprintln(diagOut, logKHyb.Stats())
+	}
+
+	if opts.stats && !logk.IsEmptyDecomp(decomp) {
+		fmt.Fprintln(diagOut, "\nWidth profile:")
+		fmt.Fprint(diagOut, profileWidth(decomp))
+	}
+
+	if opts.repeat > 0 {
+		fmt.Fprintln(resultOut, "\nRepeated runs:")
+		fmt.Fprint(resultOut, summarizeRuns(runs))
+	}
+
+	return 0
+}
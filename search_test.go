@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+	"github.com/cem-okulmus/log-k-decomp/logk"
+)
+
+func TestSearchExitCodes(t *testing.T) {
+	graph, _ := lib.GetGraph(twoTriangles) // of width 2
+
+	single := options{width: 2}
+	exact := options{exact: true}
+	exactParallel := options{exact: true, exactParallel: 2}
+	approx := options{approx: 5}
+
+	tests := []struct {
+		name      string
+		opts      options
+		solver    logk.Algorithm // nil for LogKDecomp
+		stop      string         // how the search gets stopped: cancel, deadline or memory
+		wantCode  int
+		wantWidth int // the width of the decomp found, 0 if none
+	}{
+		{"single", single, nil, "", 0, 2},
+		{"single failing", single, &countingSolver{}, "", 0, 0},
+		{"single error", single, &resultSolver{err: logk.ErrDepthExceeded}, "", exitError, 0},
+		{"single interrupted", single, &countingSolver{}, "cancel", exitInterrupt, 0},
+		{"single timed out", single, &countingSolver{}, "deadline", exitTimeout, 0},
+		{"single out of memory", single, &countingSolver{}, "memory", exitMemory, 0},
+		{"exact", exact, nil, "", 0, 2},
+		{"exact error", exact, &resultSolver{err: logk.ErrDepthExceeded}, "", exitError, 0},
+		{"exact interrupted", exact, &countingSolver{}, "cancel", exitInterrupt, 0},
+		{"exact timed out", exact, &countingSolver{}, "deadline", exitTimeout, 0},
+		{"exactparallel", exactParallel, nil, "", 0, 2},
+		{"exactparallel interrupted", exactParallel, nil, "cancel", exitInterrupt, 0},
+		{"approx", approx, nil, "", 0, 2},
+		{"approx error", approx, &resultSolver{err: logk.ErrDepthExceeded}, "", exitError, 0},
+		{"approx interrupted", approx, &countingSolver{}, "cancel", exitInterrupt, 0},
+		{"approx timed out", approx, &countingSolver{}, "deadline", exitTimeout, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.balanceFactor, opts.quiet = 2, true
+			input := &preparedGraph{original: graph, graph: graph}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			switch tt.stop {
+			case "cancel":
+				cancel()
+			case "deadline":
+				ctx, cancel = context.WithDeadline(ctx, time.Now().Add(-time.Second))
+				defer cancel()
+			}
+
+			solver := tt.solver
+			if solver == nil {
+				logK := newLogK(&opts, input, opts.width)
+				logK.SetContext(ctx)
+				solver = logK
+			}
+			s := &searcher{opts: &opts, ctx: ctx, input: input, solver: solver, connected: getConnectedComponents(graph)}
+			if tt.stop == "memory" {
+				s.memoryReached = 1
+			}
+
+			result, code := s.search(opts.width, true)
+			if code != tt.wantCode {
+				t.Fatalf("got exit code %d, want %d", code, tt.wantCode)
+			}
+			width := 0
+			if !logk.IsEmptyDecomp(result.decomp) {
+				width = result.decomp.CheckWidth()
+			}
+			if width != tt.wantWidth {
+				t.Errorf("got a decomp of width %d, want %d", width, tt.wantWidth)
+			}
+			if width > 0 && (opts.exact || opts.approx > 0) && !result.optimal {
+				t.Errorf("the width %d isn't reported as optimal", width)
+			}
+		})
+	}
+}
+
+func TestRefineKeepsDecomp(t *testing.T) {
+	graph, _ := lib.GetGraph(twoTriangles) // of width 2
+	opts := options{width: 4, refine: true, balanceFactor: 2, quiet: true}
+	input := &preparedGraph{original: graph, graph: graph}
+
+	s := &searcher{opts: &opts, ctx: context.Background(), input: input, solver: newLogK(&opts, input, opts.width),
+		connected: getConnectedComponents(graph)}
+	result, code := s.search(opts.width, true)
+	if code != 0 {
+		t.Fatalf("got exit code %d", code)
+	}
+	if logk.IsEmptyDecomp(result.decomp) || result.decomp.CheckWidth() != 2 {
+		t.Fatalf("got the decomp %v, want one of width 2", result.decomp)
+	}
+	if result.firstWidth == 0 || result.width != 2 {
+		t.Errorf("got the first width %d and the width %d, want some first width and 2", result.firstWidth,
+			result.width)
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+// parseSpecial reads the initial special edges of a graph, one special edge per line given by the names of
+// its vertices, separated by commas or whitespace, e.g. "a, b, c". Lines starting with "%" are comments.
+// Just like the special edges arising during the search, each of them ends up as a leaf of the decomp, whose
+// bag consists of exactly its vertices. Hence every vertex has to be one of the graph.
+func parseSpecial(data string, graph Graph) ([]lib.Edges, error) {
+	vertices := make(map[string]int)
+	for _, v := range graph.Vertices() {
+		vertices[vertexName(v)] = v
+	}
+
+	var output []lib.Edges
+	for i, line := range strings.Split(data, "\n") {
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\r'
+		})
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "%") {
+			continue
+		}
+
+		var special []int
+		for _, name := range fields {
+			v, ok := vertices[name]
+			if !ok {
+				return nil, fmt.Errorf("line %d: unknown vertex %q", i+1, name)
+			}
+			special = append(special, v)
+		}
+		output = append(output, lib.NewEdges([]lib.Edge{{Vertices: lib.RemoveDuplicates(special)}}))
+	}
+
+	return output, nil
+}
+
+// removeSpecial removes the leaves standing for the special edges from a decomp, s.t. the remaining tree can
+// be checked to be a decomp of the graph without them. It reports whether every special edge was found as
+// such a leaf.
+func removeSpecial(decomp Decomp) (Decomp, bool) {
+	missing := make(map[string]int)
+	for _, sp := range decomp.Graph.Special {
+		missing[specialKey(sp)]++
+	}
+
+	decomp.Root = removeSpecialLeaves(decomp.Root, missing)
+	decomp.Graph = Graph{Edges: decomp.Graph.Edges}
+
+	for _, n := range missing {
+		if n > 0 {
+			return decomp, false
+		}
+	}
+	return decomp, true
+}
+
+// removeSpecialLeaves drops the children of n covered by nothing but one of the missing special edges
+func removeSpecialLeaves(n lib.Node, missing map[string]int) lib.Node {
+	var children []lib.Node
+	for _, c := range n.Children {
+		if len(c.Children) == 0 && c.Cover.Len() == 1 && c.Cover.Slice()[0].Name == 0 {
+			if key := specialKey(c.Cover); missing[key] > 0 {
+				missing[key]--
+				continue
+			}
+		}
+		children = append(children, removeSpecialLeaves(c, missing))
+	}
+	n.Children = children
+
+	return n
+}
+
+// specialKey identifies a special edge by its vertices, regardless of their order
+func specialKey(sp lib.Edges) string {
+	vertices := append([]int{}, sp.Vertices()...)
+	sort.Ints(vertices)
+	return fmt.Sprint(vertices)
+}
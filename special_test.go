@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cem-okulmus/BalancedGo/lib"
+)
+
+func TestParseSpecial(t *testing.T) {
+	graph, _ := lib.GetGraph("e1(a,b), e2(b,c), e3(c,d).")
+
+	special, err := parseSpecial("% comment\n\na, d\nb c\tb\n", graph)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(special) != 2 {
+		t.Fatalf("got %d special edges, want 2", len(special))
+	}
+	if got := lib.PrintVertices(special[0].Vertices()); got != "(a, d)" {
+		t.Errorf("got the special edge %s, want (a, d)", got)
+	}
+	if got := len(special[1].Vertices()); got != 2 {
+		t.Errorf("got %d vertices in the special edge (b, c), want 2", got)
+	}
+
+	if _, err := parseSpecial("a, e\n", graph); err == nil || !strings.Contains(err.Error(), `line 1: unknown vertex "e"`) {
+		t.Errorf("got error %v, want the unknown vertex reported", err)
+	}
+}
+
+func TestSpecialEdges(t *testing.T) {
+	// the path a-b-c-d is acyclic, the special edge {a, d} closes it into a cycle
+	tests := []struct {
+		width string
+		want  []string
+	}{
+		{"1", []string{"Outcome: infeasible"}},
+		{"2", []string{"Special edges found as leaves:  true\n", "Width:  2\n", "Correct:  true\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run("width "+tt.width, func(t *testing.T) {
+			stdout, stderr, code := runMain(t, "-graph", "testdata/path.hg", "-special", "testdata/path.special",
+				"-logk", "-width", tt.width)
+			if code != 0 {
+				t.Fatalf("got exit code %d:\n%s", code, stderr)
+			}
+			for _, line := range tt.want {
+				if !strings.Contains(stdout, line) {
+					t.Errorf("missing %q in the output:\n%s", line, stdout)
+				}
+			}
+		})
+	}
+
+	// without the special edge, the path has a decomp of width 1
+	stdout, _, _ := runMain(t, "-graph", "testdata/path.hg", "-logk", "-width", "1")
+	if !strings.Contains(stdout, "Correct:  true\n") {
+		t.Errorf("want a decomp of width 1 without the special edge, got:\n%s", stdout)
+	}
+}
+
+func TestSpecialRejectsBounds(t *testing.T) {
+	// the bounds take the path for acyclic, of width 1, though the special edge makes it of width 2
+	for _, args := range [][]string{{"-approx", "5"}, {"-bounds"}} {
+		t.Run(args[0], func(t *testing.T) {
+			stdout, _, _ := runMain(t, append([]string{"-graph", "testdata/path.hg", "-special",
+				"testdata/path.special", "-logk"}, args...)...)
+			if !strings.Contains(stdout, "cannot be combined with -approx or -bounds") || strings.Contains(stdout, "Width") {
+				t.Errorf("want the flags rejected, got:\n%s", stdout)
+			}
+		})
+	}
+}
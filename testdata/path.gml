@@ -0,0 +1,43 @@
+graph [
+
+  directed 0
+
+  node [
+    id 8
+    label "{e2} {b, c}"
+    vgj [
+      labelPosition "in"
+      shape "Rectangle"
+    ]
+  ]
+
+  node [
+    id 9
+    label "{e3} {c, d}"
+    vgj [
+      labelPosition "in"
+      shape "Rectangle"
+    ]
+  ]
+
+  node [
+    id 10
+    label "{e1} {a, b}"
+    vgj [
+      labelPosition "in"
+      shape "Rectangle"
+    ]
+  ]
+
+  edge [
+    source 8
+    target 9
+  ]
+
+  edge [
+    source 8
+    target 10
+  ]
+
+
+]
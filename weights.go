@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cem-okulmus/log-k-decomp/logk"
+)
+
+// parseWeights reads the weights of the edges of a graph, one edge per line given by its name and its
+// weight, e.g. "R1 2.5". Lines starting with "%" are comments. Edges not listed weigh 1, and no weight may
+// be smaller than that, as the search only considers covers of at most K edges.
+func parseWeights(data string, graph Graph) (map[int]float64, error) {
+	names := make(map[string]int)
+	for _, e := range graph.Edges.Slice() {
+		names[edgeName(e)] = e.Name
+	}
+
+	weights := make(map[int]float64)
+	for i, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "%") {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected an edge name and a weight", i+1)
+		}
+
+		edge, ok := names[fields[0]]
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown edge %q", i+1, fields[0])
+		}
+		w, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil || w < 1 {
+			return nil, fmt.Errorf("line %d: invalid weight %q, must be a number of at least 1", i+1, fields[1])
+		}
+		weights[edge] = w
+	}
+
+	return weights, nil
+}
+
+// exceedsWidth returns the width of a non-empty decomp, weighted if weights are given, and whether it
+// exceeds the requested width. The search only builds nodes of width at most K, so a wider decomp means an
+// invariant broke.
+func exceedsWidth(decomp Decomp, width int, weights map[int]float64) (float64, bool) {
+	found := float64(decomp.CheckWidth())
+	if weights != nil {
+		found = logk.WeightedWidth(decomp, weights)
+	}
+	return found, found > float64(width)
+}
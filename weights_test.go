@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExceedsWidth(t *testing.T) {
+	tests := []struct {
+		name    string
+		decomp  Decomp
+		width   int
+		weights map[int]float64
+		want    float64
+		exceeds bool
+	}{
+		{"below", decompOfWidth(2), 3, nil, 2, false},
+		{"at", decompOfWidth(3), 3, nil, 3, false},
+		{"above", decompOfWidth(4), 3, nil, 4, true},
+		{"weighted at", decompOfWidth(2), 3, map[int]float64{0: 2}, 3, false},
+		{"weighted above", decompOfWidth(2), 3, map[int]float64{0: 2, 1: 1.5}, 3.5, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, exceeds := exceedsWidth(tt.decomp, tt.width, tt.weights)
+			if found != tt.want || exceeds != tt.exceeds {
+				t.Errorf("got width %g, exceeding %d: %v, want %g, %v", found, tt.width, exceeds, tt.want, tt.exceeds)
+			}
+		})
+	}
+}
+
+func TestNoWidthWarning(t *testing.T) {
+	for _, args := range [][]string{{"-width", "2"}, {"-width", "3"}, {"-exact"}, {"-approx", "10"}} {
+		stdout, stderr, code := runMain(t, append([]string{"-graph", "testdata/grid3x4.hg", "-logk"}, args...)...)
+		if code != 0 || !strings.Contains(stdout, "Correct:  true\n") {
+			t.Fatalf("%v: got exit code %d:\n%s%s", args, code, stdout, stderr)
+		}
+		if strings.Contains(stderr, "exceeding the requested width") {
+			t.Errorf("%v: got a warning about the width:\n%s", args, stderr)
+		}
+	}
+}